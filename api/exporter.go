@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/cache"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/singleflight"
+)
+
+// cacheFormat 為快取 key 的版本，匯出結果的內容改變時遞增，讓舊的快取失效
+const cacheFormat = 2
+
+// Exporter 讓每次匯出在獨立的工作目錄中進行，不寫入目前目錄；
+// 參數相同且同時進行的請求只會匯出一次並共用結果。
+// 設定 Cache 時，結果依套件 id、解析後的版本、目標 profile 與匯出選項快取。
+type Exporter struct {
+	WorkRoot string       // 工作目錄的上層目錄，空字串為系統暫存目錄
+	Cache    *cache.Cache // nil 時不快取
+
+	client *nuget.Client
+	group  singleflight.Group
+}
+
+// NewExporter 建立在 workRoot 下建立工作目錄的 Exporter
+func NewExporter(workRoot string) *Exporter {
+	return &Exporter{WorkRoot: workRoot, client: nuget.DefaultClient()}
+}
+
+type cacheKey struct {
+	Format  int
+	Source  string
+	Options internal.ExportOptions
+}
+
+// Artifact 為一次匯出的結果。使用完畢必須呼叫 Release，最後一個使用者釋放時刪除工作目錄。
+type Artifact struct {
+	Result *internal.ExportResult
+	Path   string // 產生的 .unitypackage 或 .tgz
+	Shared bool   // 結果與另一個同時進行的相同請求共用
+	Cached bool   // 結果來自快取
+
+	release func()
+}
+
+// Release 釋放 Artifact，可重複呼叫
+func (a *Artifact) Release() {
+	if a.release != nil {
+		a.release()
+	}
+}
+
+type exportOutput struct {
+	result  *internal.ExportResult
+	workDir string
+}
+
+// Export 在新的工作目錄中執行匯出；opts 的 ExportPath 與 OutputDir 會被工作目錄取代
+func (e *Exporter) Export(opts internal.ExportOptions) (*Artifact, error) {
+	opts.ExportPath = ""
+	opts.OutputDir = ""
+	if e.Cache != nil {
+		// 先解析版本，"latest" 或範圍在 feed 發佈新版後會對應到新的 key
+		versionRange, err := nuget.ParseVersionRange(opts.VersionRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package version %q: %v", opts.VersionRange, err)
+		}
+		version, err := e.client.ResolveVersion(opts.PackageID, versionRange, opts.AllowPrerelease)
+		if err != nil {
+			return nil, err
+		}
+		opts.VersionRange = "[" + version.String() + "]"
+	}
+	key, err := cache.Key(cacheKey{Format: cacheFormat, Source: e.client.SourceURL, Options: opts})
+	if err != nil {
+		return nil, err
+	}
+
+	if artifact, ok := e.cached(key); ok {
+		progress.Emit(opts.Progress, progress.Event{
+			Stage:   progress.StageDone,
+			Package: artifact.Result.PackageID,
+			Version: artifact.Result.Version,
+			Message: fmt.Sprintf("Using cached %s", filepath.Base(artifact.Path)),
+		})
+		return artifact, nil
+	}
+
+	// 同時進行的相同請求共用一份進度紀錄，每個呼叫端都收到完整的事件
+	var forwarding sync.WaitGroup
+	join := func(state interface{}) {
+		if opts.Progress == nil {
+			return
+		}
+		forwarding.Add(1)
+		go func() {
+			defer forwarding.Done()
+			progress.Forward(state.(*progress.Log), opts.Progress)
+		}()
+	}
+	val, release, shared, err := e.group.Do(key, &progress.Log{}, join, func(state interface{}) (interface{}, func(), error) {
+		events := state.(*progress.Log)
+		defer events.Close()
+
+		if e.WorkRoot != "" {
+			if err := os.MkdirAll(e.WorkRoot, os.ModePerm); err != nil {
+				return nil, nil, err
+			}
+		}
+		workDir, err := os.MkdirTemp(e.WorkRoot, "nuget_job")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create job directory: %v", err)
+		}
+		cleanup := func() { os.RemoveAll(workDir) }
+
+		jobOpts := opts
+		jobOpts.ExportPath = filepath.Join(workDir, "export")
+		jobOpts.OutputDir = workDir
+		jobOpts.Progress = progress.Multi(progress.NewConsole(os.Stdout), events)
+		result, err := internal.Export(jobOpts)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if e.Cache == nil {
+			return exportOutput{result: result, workDir: workDir}, cleanup, nil
+		}
+
+		item, err := e.Cache.Put(key, opts.PackageID, result.ArtifactPath, result)
+		if err != nil {
+			log.Printf("Not caching %s %s: %v\n", result.PackageID, result.Version, err)
+			return exportOutput{result: result, workDir: workDir}, cleanup, nil
+		}
+		cleanup()
+		result.ArtifactPath = item.Path
+		return exportOutput{result: result}, item.Release, nil
+	})
+	forwarding.Wait()
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	output := val.(exportOutput)
+	return &Artifact{
+		Result:  output.result,
+		Path:    output.result.ArtifactPath,
+		Shared:  shared,
+		release: release,
+	}, nil
+}
+
+// cached 回傳快取中 key 的結果
+func (e *Exporter) cached(key string) (*Artifact, bool) {
+	if e.Cache == nil {
+		return nil, false
+	}
+	result := &internal.ExportResult{}
+	item, ok := e.Cache.Get(key, result)
+	if !ok {
+		return nil, false
+	}
+	result.ArtifactPath = item.Path
+	return &Artifact{Result: result, Path: item.Path, Cached: true, release: item.Release}, true
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+)
+
+// newFixtureFeed 啟動只有 Fixture.Lib 1.0.0 的 V3 feed，並設定 NUGET_SOURCE 指向它。
+// 下載 nupkg 前先等待 gate（nil 時不等待），回傳已下載 nupkg 的次數
+func newFixtureFeed(t *testing.T, gate <-chan struct{}) *int32 {
+	t.Helper()
+	dll, err := os.ReadFile("../internal/clrmeta/testdata/Fixture.Lib.dll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string][]byte{
+		"Fixture.Lib.nuspec": []byte(`<?xml version="1.0" encoding="utf-8"?>
+<package><metadata><id>Fixture.Lib</id><version>1.0.0</version><description>Fixture</description></metadata></package>`),
+		"lib/netstandard2.0/Fixture.Lib.dll": dll,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	nupkg := buf.Bytes()
+
+	var downloads int32
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v3/index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version":"3.0.0","resources":[{"@id":"%s/flat/","@type":"PackageBaseAddress/3.0.0"}]}`, server.URL)
+	})
+	mux.HandleFunc("/flat/fixture.lib/index.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"versions":["1.0.0"]}`))
+	})
+	mux.HandleFunc("/flat/fixture.lib/1.0.0/fixture.lib.1.0.0.nupkg", func(w http.ResponseWriter, r *http.Request) {
+		if gate != nil {
+			select {
+			case <-gate:
+			case <-time.After(10 * time.Second):
+				t.Error("callers did not join the export")
+			}
+		}
+		atomic.AddInt32(&downloads, 1)
+		w.Write(nupkg)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("NUGET_SOURCE", server.URL+"/v3/index.json")
+	return &downloads
+}
+
+// workDirs 回傳 root 下的工作目錄
+func workDirs(t *testing.T, root string) []string {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join(root, "nuget_job*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dirs
+}
+
+func TestExporterSharesConcurrentExports(t *testing.T) {
+	const callers = 3
+	// 每個呼叫端都收到事件（已加入同一次匯出）後才讓 feed 回應下載
+	joined := make(chan struct{})
+	var pending sync.WaitGroup
+	pending.Add(callers)
+	go func() {
+		pending.Wait()
+		close(joined)
+	}()
+	downloads := newFixtureFeed(t, joined)
+	root := t.TempDir()
+	exporter := NewExporter(root)
+
+	artifacts := make([]*Artifact, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		i := i
+		var once sync.Once
+		reporter := progress.ReporterFunc(func(progress.Event) { once.Do(pending.Done) })
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			artifacts[i], errs[i] = exporter.Export(internal.ExportOptions{PackageID: "Fixture.Lib", Progress: reporter})
+		}()
+	}
+	wg.Wait()
+
+	shared := 0
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artifacts[i].Path != artifacts[0].Path {
+			t.Errorf("artifact %d is %s, want %s", i, artifacts[i].Path, artifacts[0].Path)
+		}
+		if artifacts[i].Shared {
+			shared++
+		}
+	}
+	if *downloads != 1 {
+		t.Errorf("package downloaded %d times, want 1", *downloads)
+	}
+	if shared != callers-1 {
+		t.Errorf("%d artifacts are shared, want %d", shared, callers-1)
+	}
+
+	// 工作目錄在最後一個使用者釋放後才刪除
+	dirs := workDirs(t, root)
+	if len(dirs) != 1 {
+		t.Fatalf("work directories = %v, want one", dirs)
+	}
+	for i, artifact := range artifacts {
+		if _, err := os.Stat(artifact.Path); err != nil {
+			t.Fatalf("artifact removed before release %d: %v", i+1, err)
+		}
+		artifact.Release()
+	}
+	if _, err := os.Stat(dirs[0]); !os.IsNotExist(err) {
+		t.Errorf("work directory %s was not removed after the last release", dirs[0])
+	}
+}
+
+func TestExporterSeparatesDifferentExports(t *testing.T) {
+	downloads := newFixtureFeed(t, nil)
+	root := t.TempDir()
+	exporter := NewExporter(root)
+
+	var artifacts []*Artifact
+	for _, format := range []string{internal.FormatUnityPackage, internal.FormatTarball} {
+		artifact, err := exporter.Export(internal.ExportOptions{PackageID: "Fixture.Lib", Format: format})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artifact.Shared {
+			t.Errorf("%s export was shared", format)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	if *downloads != 2 {
+		t.Errorf("package downloaded %d times, want 2", *downloads)
+	}
+	if dirs := workDirs(t, root); len(dirs) != 2 {
+		t.Errorf("work directories = %v, want two", dirs)
+	}
+
+	artifacts[0].Release()
+	if _, err := os.Stat(artifacts[1].Path); err != nil {
+		t.Errorf("releasing one export removed the other: %v", err)
+	}
+	artifacts[1].Release()
+	if dirs := workDirs(t, root); len(dirs) != 0 {
+		t.Errorf("work directories %v remain after release", dirs)
+	}
+}
@@ -1,4 +1,337 @@
 package api
 
-// 未來若提供HTTP API，可在這邊實作Handler function
-// 目前空置
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/cache"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/utils"
+)
+
+// ExportOptionsFromQuery 將 /download 與 POST /jobs 的參數（package_name、package_version、prerelease、
+// unity_version、api_level、platforms、exclude_platforms、define_constraints、explicit_reference、preload、
+// skip_analyzers、analyzer_language、allow、deny、strict_references、allow_references、link_xml、preserve、
+// asmdef_platforms、asmdef_exclude_platforms、asmdef_define_constraints、asmdef_version_defines、allow_unsafe_code、
+// no_engine_references、format、asset_root）轉為匯出選項
+func ExportOptionsFromQuery(query url.Values) (internal.ExportOptions, error) {
+	packageName := query.Get("package_name")
+	if packageName == "" {
+		return internal.ExportOptions{}, errors.New("package_name is required")
+	}
+
+	packageVersion := query.Get("package_version")
+	if packageVersion == "" {
+		packageVersion = "latest"
+	}
+
+	allowPrerelease, _ := strconv.ParseBool(query.Get("prerelease"))
+
+	profile, err := unity.ParseProfile(query.Get("unity_version"), query.Get("api_level"))
+	if err != nil {
+		return internal.ExportOptions{}, err
+	}
+	pluginSettings, err := unitypackage.NewPluginSettings(utils.SplitList(query.Get("platforms")), utils.SplitList(query.Get("exclude_platforms")))
+	if err != nil {
+		return internal.ExportOptions{}, err
+	}
+	pluginSettings.DefineConstraints = utils.SplitList(query.Get("define_constraints"))
+	pluginSettings.IsExplicitlyReferenced, _ = strconv.ParseBool(query.Get("explicit_reference"))
+	pluginSettings.IsPreloaded, _ = strconv.ParseBool(query.Get("preload"))
+	asmdefSettings, err := packagemanifest.NewAsmdefSettings(utils.SplitList(query.Get("asmdef_platforms")), utils.SplitList(query.Get("asmdef_exclude_platforms")))
+	if err != nil {
+		return internal.ExportOptions{}, err
+	}
+	asmdefSettings.DefineConstraints = utils.SplitList(query.Get("asmdef_define_constraints"))
+	if asmdefSettings.VersionDefines, err = packagemanifest.ParseVersionDefines(query.Get("asmdef_version_defines")); err != nil {
+		return internal.ExportOptions{}, err
+	}
+	asmdefSettings.AllowUnsafeCode, _ = strconv.ParseBool(query.Get("allow_unsafe_code"))
+	asmdefSettings.NoEngineReferences, _ = strconv.ParseBool(query.Get("no_engine_references"))
+	skipAnalyzers, _ := strconv.ParseBool(query.Get("skip_analyzers"))
+	strictReferences, _ := strconv.ParseBool(query.Get("strict_references"))
+	linkXML, _ := strconv.ParseBool(query.Get("link_xml"))
+
+	format := strings.ToLower(query.Get("format"))
+	if format != "" && format != internal.FormatUnityPackage && format != internal.FormatTarball {
+		return internal.ExportOptions{}, fmt.Errorf("invalid format %q: use %s or %s", format, internal.FormatUnityPackage, internal.FormatTarball)
+	}
+
+	assetRoot := query.Get("asset_root")
+	if _, err := unitypackage.NormalizeAssetRoot(assetRoot); err != nil {
+		return internal.ExportOptions{}, err
+	}
+
+	return internal.ExportOptions{
+		PackageID:        packageName,
+		VersionRange:     packageVersion,
+		AllowPrerelease:  allowPrerelease,
+		AllowAssemblies:  utils.SplitList(query.Get("allow")),
+		DenyAssemblies:   utils.SplitList(query.Get("deny")),
+		Profile:          profile,
+		PluginSettings:   &pluginSettings,
+		Asmdef:           &asmdefSettings,
+		SkipAnalyzers:    skipAnalyzers,
+		AnalyzerLanguage: query.Get("analyzer_language"),
+		StrictReferences: strictReferences,
+		AllowReferences:  utils.SplitList(query.Get("allow_references")),
+		LinkXML:          linkXML,
+		Preserve:         utils.SplitList(query.Get("preserve")),
+		Format:           format,
+		AssetRoot:        assetRoot,
+	}, nil
+}
+
+type cacheStats struct {
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+	MaxSize int64 `json:"maxSize"`
+	Purged  int   `json:"purged,omitempty"`
+}
+
+// CacheHandler 管理 artifact 快取：GET 回傳統計，DELETE 清除快取（?package_name= 只清除該套件）。
+// token 不為空時 DELETE 需要 "Authorization: Bearer <token>"。
+func CacheHandler(c *cache.Cache, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats := cacheStats{MaxSize: c.MaxSize}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodDelete:
+			if token != "" {
+				given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+					writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+					return
+				}
+			}
+			stats.Purged = c.Purge(r.URL.Query().Get("package_name"))
+		default:
+			w.Header().Set("Allow", "GET, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		stats.Entries, stats.Size = c.Stats()
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+// JobsHandler 提供非同步匯出：
+//
+//	POST /jobs                建立工作，參數同 /download（query、form 或 JSON body），回傳 202 與工作狀態
+//	GET  /jobs/{id}           工作狀態、選中的框架、警告與檔案清單
+//	GET  /jobs/{id}/artifact  下載結果
+//	GET  /jobs/{id}/events    以 Server-Sent Events 串流進度事件，工作結束時送出 status 事件
+func JobsHandler(jobs *JobManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+		parts := strings.Split(path, "/")
+		switch {
+		case path == "":
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+				return
+			}
+			submitJob(jobs, w, r)
+		case r.Method != http.MethodGet && r.Method != http.MethodHead:
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		case len(parts) == 1:
+			job, ok := jobs.Get(parts[0])
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, jobs.Status(job))
+		case len(parts) == 2 && parts[1] == "artifact":
+			job, ok := jobs.Get(parts[0])
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+				return
+			}
+			serveJobArtifact(jobs, job, w, r)
+		case len(parts) == 2 && parts[1] == "events":
+			job, ok := jobs.Get(parts[0])
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+				return
+			}
+			streamJobEvents(jobs, job, w, r)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+	}
+}
+
+func submitJob(jobs *JobManager, w http.ResponseWriter, r *http.Request) {
+	query, err := jobParameters(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	opts, err := ExportOptionsFromQuery(query)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	job, err := jobs.Submit(opts)
+	if errors.Is(err, ErrQueueFull) {
+		w.Header().Set("Retry-After", "30")
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, jobs.Status(job))
+}
+
+// jobParameters 讀取 POST /jobs 的參數：JSON body 的值可為字串、數字、布林或字串陣列，其餘使用 query 與 form
+func jobParameters(r *http.Request) (url.Values, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return r.Form, nil
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	query := r.URL.Query()
+	for key, value := range body {
+		switch v := value.(type) {
+		case string:
+			query.Set(key, v)
+		case bool:
+			query.Set(key, strconv.FormatBool(v))
+		case float64:
+			query.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s must be a list of strings", key)
+				}
+				items = append(items, s)
+			}
+			query.Set(key, strings.Join(items, ","))
+		case nil:
+		default:
+			return nil, fmt.Errorf("unsupported value for %s", key)
+		}
+	}
+	return query, nil
+}
+
+func serveJobArtifact(jobs *JobManager, job *Job, w http.ResponseWriter, r *http.Request) {
+	var content *os.File
+	var name string
+	var size int64
+	found, err := job.OpenArtifact(func(artifact *Artifact) error {
+		f, err := os.Open(artifact.Path)
+		if err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		content, name, size = f, filepath.Base(artifact.Path), info.Size()
+		return nil
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !found {
+		status := jobs.Status(job)
+		if status.State == JobFailed {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "job failed: " + status.Error})
+		} else {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "job is " + string(status.State)})
+		}
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	if strings.HasSuffix(name, ".tgz") {
+		w.Header().Set("Content-Type", "application/gzip")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error sending artifact of job %s: %v\n", job.ID, err)
+	}
+}
+
+// streamJobEvents 以 SSE 送出工作從頭（或 Last-Event-ID 之後）的 progress 事件，工作結束時送出 status 事件後關閉
+func streamJobEvents(jobs *JobManager, job *Job, w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming unsupported"})
+		return
+	}
+	next := 0
+	if last, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && last >= 0 {
+		next = last + 1
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		events, closed, wait := job.Events().Since(next)
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: progress\ndata: %s\n\n", next, data); err != nil {
+				return
+			}
+			next++
+		}
+		if closed {
+			data, err := json.Marshal(jobs.Status(job))
+			if err == nil {
+				fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+			}
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-wait:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
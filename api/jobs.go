@@ -0,0 +1,259 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+)
+
+// JobState 為非同步匯出工作的狀態
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// ErrQueueFull 表示等待中的工作已達上限
+var ErrQueueFull = errors.New("export queue is full")
+
+// Job 為一個非同步匯出工作
+type Job struct {
+	ID      string
+	Options internal.ExportOptions
+
+	seq      int64
+	events   progress.Log
+	mu       sync.Mutex
+	state    JobState
+	err      error
+	artifact *Artifact
+	created  time.Time
+	started  time.Time
+	finished time.Time
+}
+
+// JobStatus 為 GET /jobs/{id} 回傳的工作狀態
+type JobStatus struct {
+	ID              string      `json:"id"`
+	State           JobState    `json:"state"`
+	Progress        JobProgress `json:"progress"`
+	PackageID       string      `json:"packageId"`
+	Version         string      `json:"version,omitempty"`
+	Framework       string      `json:"framework,omitempty"`
+	FrameworkReason string      `json:"frameworkReason,omitempty"`
+	Warnings        []string    `json:"warnings"`
+	Files           []string    `json:"files"`
+	Error           string      `json:"error,omitempty"`
+	Artifact        string      `json:"artifact,omitempty"` // 下載結果的路徑
+	Cached          bool        `json:"cached,omitempty"`
+	Created         time.Time   `json:"created"`
+	Started         *time.Time  `json:"started,omitempty"`
+	Finished        *time.Time  `json:"finished,omitempty"`
+}
+
+// JobProgress 為工作的進度，除排隊位置外取自最後一個事件
+type JobProgress struct {
+	QueuePosition int            `json:"queuePosition,omitempty"` // 排隊中時前面還有幾個工作（含自己）
+	Stage         progress.Stage `json:"stage,omitempty"`
+	Message       string         `json:"message,omitempty"`
+	Package       string         `json:"package,omitempty"`
+	Bytes         int64          `json:"bytes,omitempty"`
+	Total         int64          `json:"total,omitempty"`
+}
+
+// JobManager 以固定數量的 worker 執行匯出工作，完成的工作保留 Retention 後刪除
+type JobManager struct {
+	Exporter  *Exporter
+	Retention time.Duration
+
+	queue    chan *Job
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	nextSeq  int64
+	dequeued int64
+}
+
+// NewJobManager 建立並啟動 workers 個 worker 的 JobManager，最多 queueSize 個工作排隊
+func NewJobManager(exporter *Exporter, workers, queueSize int, retention time.Duration) *JobManager {
+	if workers < 1 {
+		workers = 1
+	}
+	m := &JobManager{
+		Exporter:  exporter,
+		Retention: retention,
+		queue:     make(chan *Job, queueSize),
+		jobs:      map[string]*Job{},
+	}
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Submit 將匯出工作排入佇列；佇列已滿時回傳 ErrQueueFull
+func (m *JobManager) Submit(opts internal.ExportOptions) (*Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{ID: id, Options: opts, state: JobQueued, created: time.Now()}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextSeq++
+	job.seq = m.nextSeq
+	select {
+	case m.queue <- job:
+	default:
+		m.nextSeq--
+		return nil, ErrQueueFull
+	}
+	m.jobs[id] = job
+	return job, nil
+}
+
+// Get 回傳 id 的工作
+func (m *JobManager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// Status 回傳 job 目前的狀態
+func (m *JobManager) Status(job *Job) JobStatus {
+	m.mu.Lock()
+	dequeued := m.dequeued
+	m.mu.Unlock()
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	status := JobStatus{
+		ID:        job.ID,
+		State:     job.state,
+		PackageID: job.Options.PackageID,
+		Warnings:  []string{},
+		Files:     []string{},
+		Created:   job.created,
+	}
+	if job.state == JobQueued {
+		status.Progress.QueuePosition = int(job.seq - dequeued)
+	}
+	if last, ok := job.events.Last(); ok {
+		status.Progress.Stage = last.Stage
+		status.Progress.Message = last.Message
+		status.Progress.Package = last.Package
+		status.Progress.Bytes = last.Bytes
+		status.Progress.Total = last.Total
+	}
+	if !job.started.IsZero() {
+		started := job.started
+		status.Started = &started
+	}
+	if !job.finished.IsZero() {
+		finished := job.finished
+		status.Finished = &finished
+	}
+	if job.err != nil {
+		status.Error = job.err.Error()
+	}
+	if job.artifact != nil {
+		result := job.artifact.Result
+		status.Version = result.Version
+		status.Framework = result.Framework.Folder
+		status.FrameworkReason = result.Framework.Reason
+		if result.Warnings != nil {
+			status.Warnings = result.Warnings
+		}
+		if result.Files != nil {
+			status.Files = result.Files
+		}
+		status.Artifact = "/jobs/" + job.ID + "/artifact"
+		status.Cached = job.artifact.Cached
+	}
+	return status
+}
+
+// Events 回傳工作的事件紀錄，工作結束時關閉
+func (job *Job) Events() *progress.Log {
+	return &job.events
+}
+
+// OpenArtifact 在工作成功時呼叫 open 開啟結果；工作在 open 期間不會被刪除
+func (job *Job) OpenArtifact(open func(*Artifact) error) (bool, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.artifact == nil {
+		return false, nil
+	}
+	return true, open(job.artifact)
+}
+
+func (m *JobManager) work() {
+	for job := range m.queue {
+		m.mu.Lock()
+		m.dequeued = job.seq
+		m.mu.Unlock()
+
+		job.mu.Lock()
+		job.state = JobRunning
+		job.started = time.Now()
+		job.mu.Unlock()
+
+		opts := job.Options
+		opts.Progress = &job.events
+		artifact, err := m.Exporter.Export(opts)
+
+		job.mu.Lock()
+		job.finished = time.Now()
+		if err != nil {
+			job.state = JobFailed
+			job.err = err
+		} else {
+			job.state = JobSucceeded
+			job.artifact = artifact
+		}
+		job.mu.Unlock()
+		if err != nil {
+			progress.Emit(&job.events, progress.Event{Stage: progress.StageDone, Package: opts.PackageID, Message: err.Error(), Warning: true})
+		}
+		job.events.Close()
+
+		id := job.ID
+		time.AfterFunc(m.Retention, func() { m.remove(id) })
+	}
+}
+
+// remove 刪除工作並釋放其結果
+func (m *JobManager) remove(id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	delete(m.jobs, id)
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.artifact != nil {
+		job.artifact.Release()
+		job.artifact = nil
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+)
+
+// waitJob 等待 job 進入 state
+func waitJob(t *testing.T, m *JobManager, job *Job, state JobState) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		status := m.Status(job)
+		if status.State == state {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", job.ID, status.State, state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestJobManagerQueueFull(t *testing.T) {
+	// 第一個工作在下載時停住，讓第二個工作留在佇列中
+	gate := make(chan struct{})
+	newFixtureFeed(t, gate)
+	m := NewJobManager(NewExporter(t.TempDir()), 1, 1, time.Hour)
+	opts := internal.ExportOptions{PackageID: "Fixture.Lib"}
+
+	running, err := m.Submit(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitJob(t, m, running, JobRunning)
+	queued, err := m.Submit(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := m.Status(queued); status.State != JobQueued || status.Progress.QueuePosition != 1 {
+		t.Errorf("queued job is %s at position %d, want queued at 1", status.State, status.Progress.QueuePosition)
+	}
+	if _, err := m.Submit(opts); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want ErrQueueFull", err)
+	}
+
+	close(gate)
+	for _, job := range []*Job{running, queued} {
+		status := waitJob(t, m, job, JobSucceeded)
+		if status.Version != "1.0.0" || status.Artifact != "/jobs/"+job.ID+"/artifact" {
+			t.Errorf("job %s = version %q, artifact %q", job.ID, status.Version, status.Artifact)
+		}
+	}
+	// 佇列有空位後可以再提交
+	job, err := m.Submit(opts)
+	if err != nil {
+		t.Fatalf("Submit() after the queue drained: %v", err)
+	}
+	waitJob(t, m, job, JobSucceeded)
+}
+
+func TestJobManagerRetention(t *testing.T) {
+	newFixtureFeed(t, nil)
+	const retention = 100 * time.Millisecond
+	m := NewJobManager(NewExporter(t.TempDir()), 1, 1, retention)
+
+	job, err := m.Submit(internal.ExportOptions{PackageID: "Fixture.Lib"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitJob(t, m, job, JobSucceeded)
+	var path string
+	if ok, _ := job.OpenArtifact(func(a *Artifact) error { path = a.Path; return nil }); !ok {
+		t.Fatal("succeeded job has no artifact")
+	}
+	if _, ok := m.Get(job.ID); !ok {
+		t.Fatal("job was removed before its retention expired")
+	}
+
+	// 保留期限過後工作被刪除，結果也被釋放
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, ok := m.Get(job.ID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job was not removed after its retention expired")
+		}
+		time.Sleep(retention / 4)
+	}
+	if ok, _ := job.OpenArtifact(func(*Artifact) error { return nil }); ok {
+		t.Error("removed job still has an artifact")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("artifact %s was not removed: %v", path, err)
+	}
+}
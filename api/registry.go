@@ -0,0 +1,453 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// registryScope 為 registry 提供的 UPM 套件名稱前綴，對應 scopedRegistries 的 scopes
+const registryScope = "com.nuget"
+
+// Registry 以 Unity Package Manager 使用的 npm registry 協定子集合提供 NuGet 套件，
+// 套件在下載 tarball 時才轉換。NuGet 依賴對應為 com.nuget.* 的 UPM 依賴，每個 tarball 只含套件本身的組件：
+//
+//	GET /{name}                    packument（版本清單與 tarball 網址）
+//	GET /{name}/{version}          單一版本的 manifest
+//	GET /{name}/-/{name}-{ver}.tgz UPM 套件
+//	GET /-/v1/search?text=         搜尋
+type Registry struct {
+	Client   *nuget.Client
+	Exporter *Exporter
+	Profile  unity.Profile // 轉換時使用的 Unity 版本與 API level
+	BaseURL  string        // tarball 網址的前綴，空字串時依請求的 Host 產生
+
+	mu       sync.Mutex
+	versions map[string]*registryVersion // "<小寫 id>@<版本>" → 已解析的版本資訊
+}
+
+// NewRegistry 建立使用 client 查詢 NuGet feed、以 exporter 轉換套件的 registry
+func NewRegistry(client *nuget.Client, exporter *Exporter, profile unity.Profile) *Registry {
+	return &Registry{Client: client, Exporter: exporter, Profile: profile}
+}
+
+type packument struct {
+	ID          string                      `json:"_id"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description,omitempty"`
+	DistTags    map[string]string           `json:"dist-tags"`
+	Versions    map[string]packumentVersion `json:"versions"`
+}
+
+type packumentVersion struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	DisplayName  string            `json:"displayName"`
+	Description  string            `json:"description,omitempty"`
+	Unity        string            `json:"unity"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Dist         packumentDist     `json:"dist"`
+}
+
+type packumentDist struct {
+	Tarball string `json:"tarball"`
+}
+
+type searchResponse struct {
+	Objects []searchObject `json:"objects"`
+	Total   int            `json:"total"`
+	Time    string         `json:"time"`
+}
+
+type searchObject struct {
+	Package     searchPackage `json:"package"`
+	Score       searchScore   `json:"score"`
+	SearchScore float64       `json:"searchScore"`
+}
+
+type searchPackage struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	DisplayName string            `json:"displayName"`
+	Description string            `json:"description,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	Links       map[string]string `json:"links,omitempty"`
+}
+
+type searchScore struct {
+	Final  float64            `json:"final"`
+	Detail map[string]float64 `json:"detail"`
+}
+
+// ServeHTTP 依路徑分派 packument、tarball 與搜尋請求
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeRegistryError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "-/v1/search":
+		reg.search(w, r)
+	case strings.Contains(path, "/-/"):
+		parts := strings.SplitN(path, "/-/", 2)
+		reg.tarball(w, r, parts[0], parts[1])
+	case path != "" && !strings.Contains(path, "/"):
+		reg.packument(w, r, path)
+	case strings.Count(path, "/") == 1 && !strings.HasPrefix(path, "-/"):
+		parts := strings.SplitN(path, "/", 2)
+		reg.versionManifest(w, r, parts[0], parts[1])
+	default:
+		writeRegistryError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// resolvePackage 找出 UPM 名稱對應的 NuGet 套件 id 與其版本
+func (reg *Registry) resolvePackage(name string) (string, []nuget.Version, bool) {
+	for _, id := range packagemanifest.PackageIDCandidates(name) {
+		versions, err := reg.Client.Versions(id)
+		if err == nil && len(versions) > 0 {
+			return id, versions, true
+		}
+	}
+	return "", nil, false
+}
+
+func (reg *Registry) packument(w http.ResponseWriter, r *http.Request, name string) {
+	id, versions, ok := reg.resolvePackage(name)
+	if !ok {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("package %s not found", name))
+		return
+	}
+
+	// 有 revision 的版本（1.2.3.4）無法以 UPM 版本表示，不列出
+	var listed []nuget.Version
+	for _, v := range versions {
+		if _, err := packagemanifest.UPMVersion(v.String()); err == nil {
+			listed = append(listed, v)
+		}
+	}
+	if len(listed) == 0 {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("package %s has no version that UPM can install", name))
+		return
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Compare(listed[j]) < 0 })
+
+	// latest 為最新的穩定版；只有 prerelease 時使用最新的 prerelease
+	latest := listed[len(listed)-1]
+	for i := len(listed) - 1; i >= 0; i-- {
+		if !listed[i].IsPrerelease() {
+			latest = listed[i]
+			break
+		}
+	}
+
+	// 只解析 latest 的依賴；其他版本的依賴在請求該版本的 manifest 或 tarball 時才解析，
+	// 已解析過的版本會一併列出
+	info, err := reg.versionInfo(id, latest)
+	if err != nil {
+		writeResolveError(w, id, latest.String(), err)
+		return
+	}
+
+	latestVersion, _ := packagemanifest.UPMVersion(latest.String())
+	doc := packument{
+		ID:          name,
+		Name:        name,
+		Description: info.Description,
+		DistTags:    map[string]string{"latest": latestVersion},
+		Versions:    map[string]packumentVersion{},
+	}
+	base := reg.baseURL(r)
+	for _, v := range listed {
+		entry := reg.versionEntry(base, name, v, info)
+		if cached, ok := reg.cachedVersionInfo(id, v); ok {
+			entry.Dependencies = cached.Dependencies
+		}
+		doc.Versions[entry.Version] = entry
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// versionManifest 回傳單一版本的 manifest（GET /{name}/{version}），依賴在此時解析
+func (reg *Registry) versionManifest(w http.ResponseWriter, r *http.Request, name, upmVersion string) {
+	version, err := packagemanifest.NuGetVersion(upmVersion)
+	if err != nil {
+		writeRegistryError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	v, err := nuget.ParseVersion(version)
+	if err != nil {
+		writeRegistryError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	id, versions, ok := reg.resolvePackage(name)
+	if !ok || !containsVersion(versions, v) {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("version %s of %s not found", upmVersion, name))
+		return
+	}
+	info, err := reg.versionInfo(id, v)
+	if err != nil {
+		writeResolveError(w, id, v.String(), err)
+		return
+	}
+	entry := reg.versionEntry(reg.baseURL(r), name, v, info)
+	entry.Dependencies = info.Dependencies
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// versionEntry 建立 v 的 packument 項目，名稱與說明取自 info，依賴留空由呼叫端填入
+func (reg *Registry) versionEntry(base, name string, v nuget.Version, info *registryVersion) packumentVersion {
+	upmVersion, _ := packagemanifest.UPMVersion(v.String())
+	return packumentVersion{
+		Name:        name,
+		Version:     upmVersion,
+		DisplayName: info.DisplayName,
+		Description: info.Description,
+		Unity:       reg.profile().UnityVersion.String(),
+		Dist:        packumentDist{Tarball: fmt.Sprintf("%s/%s/-/%s-%s.tgz", base, name, name, upmVersion)},
+	}
+}
+
+// writeResolveError 回傳 versionInfo 的錯誤：版本不存在時為 404，其他（feed 或依賴解析失敗）為 502
+func writeResolveError(w http.ResponseWriter, id, version string, err error) {
+	if errors.Is(err, nuget.ErrPackageNotFound) {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("version %s of %s not found", version, id))
+		return
+	}
+	log.Printf("Error resolving %s %s: %v\n", id, version, err)
+	writeRegistryError(w, http.StatusBadGateway, fmt.Sprintf("failed to resolve the dependencies of %s %s", id, version))
+}
+
+func containsVersion(versions []nuget.Version, v nuget.Version) bool {
+	for _, candidate := range versions {
+		if candidate.Compare(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// registryVersion 為一個版本的名稱、說明與 UPM 依賴（與 tarball 的 package.json 相同）
+type registryVersion struct {
+	DisplayName  string
+	Description  string
+	Dependencies map[string]string
+}
+
+func versionKey(id string, v nuget.Version) string {
+	return strings.ToLower(id) + "@" + v.String()
+}
+
+// cachedVersionInfo 回傳已解析過的版本資訊
+func (reg *Registry) cachedVersionInfo(id string, v nuget.Version) (*registryVersion, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	info, ok := reg.versions[versionKey(id, v)]
+	return info, ok
+}
+
+// profile 回傳轉換使用的 Profile，零值為 unity.DefaultProfile
+func (reg *Registry) profile() unity.Profile {
+	if reg.Profile.IsZero() {
+		return unity.DefaultProfile
+	}
+	return reg.Profile
+}
+
+// versionInfo 下載 v 的 nuspec 並解析其 UPM 依賴；成功的結果會被快取（nuspec 不會改變因此不過期），
+// 失敗時回傳錯誤而不快取
+func (reg *Registry) versionInfo(id string, v nuget.Version) (*registryVersion, error) {
+	if info, ok := reg.cachedVersionInfo(id, v); ok {
+		return info, nil
+	}
+	spec, err := reg.Client.DownloadNuspec(id, v.String())
+	if err != nil {
+		return nil, err
+	}
+	profile := reg.profile()
+	resolver := nuget.NewResolver(reg.Client, "")
+	resolver.Filter = unity.NewAssemblyFilter(profile, nil, nil)
+	deps, err := internal.UPMDependencies(resolver, spec, nuget.ProfileTargets(profile))
+	if err != nil {
+		// 依賴找不到不代表這個版本不存在，不保留 ErrPackageNotFound
+		return nil, fmt.Errorf("%v", err)
+	}
+
+	info := &registryVersion{DisplayName: id, Description: "Auto-generated package for " + id, Dependencies: deps}
+	if spec.Metadata.ID != "" {
+		info.DisplayName = spec.Metadata.ID
+	}
+	if spec.Metadata.Description != "" {
+		info.Description = spec.Metadata.Description
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.versions == nil {
+		reg.versions = map[string]*registryVersion{}
+	}
+	reg.versions[versionKey(id, v)] = info
+	return info, nil
+}
+
+func (reg *Registry) tarball(w http.ResponseWriter, r *http.Request, name, file string) {
+	upmVersion := strings.TrimSuffix(strings.TrimPrefix(file, name+"-"), ".tgz")
+	if !strings.HasPrefix(file, name+"-") || !strings.HasSuffix(file, ".tgz") || upmVersion == "" {
+		writeRegistryError(w, http.StatusNotFound, "not found")
+		return
+	}
+	version, err := packagemanifest.NuGetVersion(upmVersion)
+	if err != nil {
+		writeRegistryError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	id, _, ok := reg.resolvePackage(name)
+	if !ok {
+		writeRegistryError(w, http.StatusNotFound, fmt.Sprintf("package %s not found", name))
+		return
+	}
+	// 先解析並快取依賴，之後的 packument 會列出這個版本的依賴
+	if v, err := nuget.ParseVersion(version); err == nil {
+		if _, err := reg.versionInfo(id, v); err != nil {
+			writeResolveError(w, id, version, err)
+			return
+		}
+	}
+
+	artifact, err := reg.Exporter.Export(internal.ExportOptions{
+		PackageID:    id,
+		VersionRange: "[" + version + "]",
+		Format:       internal.FormatTarball,
+		Profile:      reg.Profile,
+		// 依賴由 Unity 依 package.json 另外從 registry 安裝
+		UPMDependencies: true,
+	})
+	if err != nil {
+		log.Printf("Error converting %s %s: %v\n", id, version, err)
+		writeRegistryError(w, http.StatusInternalServerError, fmt.Sprintf("failed to convert %s %s", id, version))
+		return
+	}
+	defer artifact.Release()
+
+	content, err := os.Open(artifact.Path)
+	if err != nil {
+		writeRegistryError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer content.Close()
+	info, err := content.Stat()
+	if err != nil {
+		writeRegistryError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error sending %s: %v\n", file, err)
+	}
+}
+
+func (reg *Registry) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	text := strings.TrimSpace(query.Get("text"))
+	// Unity 以 scope 本身搜尋時列出熱門套件；以完整 UPM 名稱搜尋時轉回 NuGet id
+	if text == registryScope || text == registryScope+"." {
+		text = ""
+	} else if ids := packagemanifest.PackageIDCandidates(text); ids != nil {
+		text = ids[0]
+	}
+	from, _ := strconv.Atoi(query.Get("from"))
+	size, err := strconv.Atoi(query.Get("size"))
+	if err != nil || size <= 0 {
+		size = 20
+	}
+	if size > 250 {
+		size = 250
+	}
+	if from < 0 {
+		from = 0
+	}
+
+	result, err := reg.Client.Search(text, from, size, false)
+	if err != nil {
+		log.Printf("Error searching for %q: %v\n", text, err)
+		writeRegistryError(w, http.StatusBadGateway, "search failed")
+		return
+	}
+
+	response := searchResponse{Objects: []searchObject{}, Total: result.TotalHits, Time: time.Now().UTC().Format(time.RFC3339)}
+	for _, pkg := range result.Data {
+		version, err := packagemanifest.UPMVersion(pkg.Version)
+		if err != nil {
+			// 最新版有 revision 等無法以 UPM 版本表示的套件
+			continue
+		}
+		found := searchPackage{
+			Name:        packagemanifest.PackageName(pkg.ID),
+			Version:     version,
+			DisplayName: pkg.ID,
+			Description: pkg.Description,
+			Keywords:    pkg.Tags,
+		}
+		if pkg.ProjectURL != "" {
+			found.Links = map[string]string{"homepage": pkg.ProjectURL}
+		}
+		response.Objects = append(response.Objects, searchObject{
+			Package:     found,
+			Score:       searchScore{Final: 1, Detail: map[string]float64{"quality": 1, "popularity": 1, "maintenance": 1}},
+			SearchScore: 1,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// baseURL 回傳 tarball 網址的前綴，優先使用設定值，其次依反向代理標頭與請求的 Host 產生
+func (reg *Registry) baseURL(r *http.Request) string {
+	if reg.BaseURL != "" {
+		return strings.TrimSuffix(reg.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v\n", err)
+	}
+}
+
+// writeRegistryError 以 npm registry 的格式回傳錯誤
+func writeRegistryError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -1,4 +1,24 @@
 package api
 
-// 未來若提供HTTP API，可在這裡定義路由設定
-// 目前空置
+import (
+	"net/http"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/cache"
+)
+
+// RegisterRegistry 將 npm registry 掛在根路徑下，其他較明確的路由（如 /download）仍優先比對
+func RegisterRegistry(mux *http.ServeMux, registry *Registry) {
+	mux.Handle("/", registry)
+}
+
+// RegisterCache 掛上 /cache 快取管理端點
+func RegisterCache(mux *http.ServeMux, c *cache.Cache, token string) {
+	mux.Handle("/cache", CacheHandler(c, token))
+}
+
+// RegisterJobs 掛上 /jobs 非同步匯出端點
+func RegisterJobs(mux *http.ServeMux, jobs *JobManager) {
+	handler := JobsHandler(jobs)
+	mux.Handle("/jobs", handler)
+	mux.Handle("/jobs/", handler)
+}
@@ -7,12 +7,61 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/api"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/cache"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
 )
 
+// minJobRetention 為 JOB_RETENTION 的下限，讓提交者有時間查詢結果並下載
+const minJobRetention = time.Minute
+
 func main() {
-	http.HandleFunc("/download", downloadHandler)
+	// 每次匯出都在 WORK_DIR（預設系統暫存目錄）下的獨立工作目錄進行
+	exporter := api.NewExporter(os.Getenv("WORK_DIR"))
+	artifactCache, err := openCache()
+	if err != nil {
+		log.Fatalf("Invalid cache configuration: %v", err)
+	}
+	if artifactCache != nil {
+		exporter.Cache = artifactCache
+		api.RegisterCache(http.DefaultServeMux, artifactCache, os.Getenv("CACHE_ADMIN_TOKEN"))
+		log.Printf("Caching artifacts in %s\n", artifactCache.Dir)
+	}
+	http.HandleFunc("/download", downloadHandler(exporter))
+
+	// 非同步匯出：JOB_WORKERS 個 worker、最多 JOB_QUEUE_SIZE 個工作排隊，完成的工作保留 JOB_RETENTION
+	workers, err := envInt("JOB_WORKERS", 2)
+	if err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	queueSize, err := envInt("JOB_QUEUE_SIZE", 100)
+	if err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	retention, err := envDuration("JOB_RETENTION", time.Hour)
+	if err != nil {
+		log.Fatalf("Invalid job configuration: %v", err)
+	}
+	if retention < minJobRetention {
+		log.Fatalf("Invalid job configuration: JOB_RETENTION must be at least %s", minJobRetention)
+	}
+	api.RegisterJobs(http.DefaultServeMux, api.NewJobManager(exporter, workers, queueSize, retention))
+
+	// Unity Package Manager 的 scoped registry：UNITY_VERSION 與 UNITY_API_LEVEL 決定轉換的目標，
+	// REGISTRY_URL 為對外的網址（預設依請求的 Host 產生）
+	profile, err := unity.ParseProfile(os.Getenv("UNITY_VERSION"), os.Getenv("UNITY_API_LEVEL"))
+	if err != nil {
+		log.Fatalf("Invalid registry profile: %v", err)
+	}
+	registry := api.NewRegistry(nuget.DefaultClient(), exporter, profile)
+	registry.BaseURL = os.Getenv("REGISTRY_URL")
+	api.RegisterRegistry(http.DefaultServeMux, registry)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -21,28 +70,81 @@ func main() {
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
 
-func downloadHandler(w http.ResponseWriter, r *http.Request) {
-	packageName := r.URL.Query().Get("package_name")
-	if packageName == "" {
-		http.Error(w, "package_name is required", http.StatusBadRequest)
-		return
+// openCache 依環境變數建立 artifact 快取：
+// CACHE_DIR（預設使用者快取目錄）、CACHE_MAX_SIZE（MB，預設 1024，0 停用快取）、CACHE_MAX_AGE（預設 168h）
+func openCache() (*cache.Cache, error) {
+	maxSize, err := envInt("CACHE_MAX_SIZE", 1024)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize == 0 {
+		return nil, nil
+	}
+	maxAge, err := envDuration("CACHE_MAX_AGE", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
 	}
 
-	packageVersion := r.URL.Query().Get("package_version")
-	if packageVersion == "" {
-		packageVersion = "latest"
+	dir := os.Getenv("CACHE_DIR")
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "nuget2unitypackage")
+	}
+	return cache.Open(dir, int64(maxSize)*1024*1024, maxAge)
+}
+
+// envInt 讀取非負整數的環境變數，未設定時回傳 fallback
+func envInt(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// envDuration 讀取時間長度（如 "72h"）的環境變數，未設定時回傳 fallback
+func envDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return d, nil
+}
+
+func downloadHandler(exporter *api.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		download(exporter, w, r)
+	}
+}
+
+func download(exporter *api.Exporter, w http.ResponseWriter, r *http.Request) {
+	opts, err := api.ExportOptionsFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	exportDir := "./export"
-	err := internal.ExportNugetPackageToUnity(packageName, packageVersion, exportDir)
+	artifact, err := exporter.Export(opts)
 	if err != nil {
 		log.Printf("Error exporting package: %v\n", err)
 		http.Error(w, "Failed to export unitypackage", http.StatusInternalServerError)
 		return
 	}
 
-	unityPackageName := packageName + ".unitypackage"
-	unityPackagePath := filepath.Join(".", unityPackageName)
+	defer artifact.Release()
+	result := artifact.Result
+	unityPackagePath := artifact.Path
 
 	fileInfo, err := os.Stat(unityPackagePath)
 	if os.IsNotExist(err) {
@@ -54,8 +156,20 @@ func downloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("X-Package-Version", result.Version)
+	if artifact.Cached {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.Header().Set("X-Selected-Framework", result.Framework.Folder)
+	w.Header().Set("X-Framework-Reason", result.Framework.Reason)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileInfo.Name()))
-	w.Header().Set("Content-Type", "application/octet-stream")
+	if strings.HasSuffix(unityPackagePath, ".tgz") {
+		w.Header().Set("Content-Type", "application/gzip")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", fileInfo.Size()))
 
 	file, err := os.Open(unityPackagePath)
@@ -69,7 +183,4 @@ func downloadHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Error sending file: %v\n", err)
 	}
-
-	os.Remove(unityPackagePath)
-
 }
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// AssemblyResult 為匯出的 .NET 組件，由 DLL 的 metadata 讀出
+type AssemblyResult struct {
+	Path string // 套件內以 "/" 分隔的相對路徑
+	clrmeta.Assembly
+}
+
+// inspectAssemblies 讀取 pluginPath/Runtime 下每個 DLL 的 metadata，回傳組件與警告：
+// 參考組件（執行時無法載入）、檔名與組件名稱不同，以及目標框架不被 profile 支援
+func inspectAssemblies(pluginPath string, profile unity.Profile, targets []nuget.Framework, report progress.Reporter) ([]AssemblyResult, []string, error) {
+	var assemblies []AssemblyResult
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warning := fmt.Sprintf(format, args...)
+		progress.Warnf(report, progress.StageCopy, "%s", warning)
+		warnings = append(warnings, warning)
+	}
+
+	err := filepath.WalkDir(filepath.Join(pluginPath, "Runtime"), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".dll") {
+			return err
+		}
+		rel, err := filepath.Rel(pluginPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		assembly, err := clrmeta.ReadFile(p)
+		if errors.Is(err, clrmeta.ErrNotAssembly) {
+			progress.Infof(report, progress.StageCopy, "Skipping metadata of %s: not a .NET assembly", rel)
+			return nil
+		}
+		if err != nil {
+			warn("Cannot read the metadata of %s: %v", rel, err)
+			return nil
+		}
+		assemblies = append(assemblies, AssemblyResult{Path: rel, Assembly: *assembly})
+
+		fileName := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+		if !strings.EqualFold(fileName, assembly.Name) {
+			warn("%s contains assembly %s; Unity resolves assemblies by name, so references to %s may fail", rel, assembly.Name, fileName)
+		}
+		if assembly.IsReferenceAssembly {
+			warn("%s is a reference assembly and cannot be loaded at runtime", rel)
+		}
+		if assembly.TargetFramework != "" {
+			if fw, err := nuget.ParseFramework(assembly.TargetFramework); err == nil && !supportsFramework(targets, fw) {
+				warn("%s targets %s, which %s does not support", rel, assembly.TargetFramework, profile)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return assemblies, warnings, nil
+}
+
+// supportsFramework 回傳 targets 中是否有可使用 fw 的目標框架
+func supportsFramework(targets []nuget.Framework, fw nuget.Framework) bool {
+	for _, target := range targets {
+		if nuget.IsCompatible(target, fw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/manifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
+)
+
+// BatchResult 為批次匯出的結果
+type BatchResult struct {
+	Profile  unity.Profile
+	Exports  []*ExportResult // 依清單順序，每個套件一個輸出
+	Skipped  []string        // 只被 exclude 它的套件依賴、因此沒有匯出的套件
+	Warnings []string        // 依賴圖的警告
+
+	ArtifactPath string // Bundle 的 .unitypackage 路徑，沒有 Bundle 時為空字串
+}
+
+// ExportBatch 在同一次執行中匯出 packages 中的每個套件，opts 的 PackageID、VersionRange 與 Framework 不使用。
+// 所有套件共用下載目錄與同一個依賴圖：共用的依賴只選擇一個版本，並且只匯出一次，
+// 放在清單中第一個依賴它的套件的輸出中。opts.Bundle 不為空時所有套件打包成同一個 .unitypackage
+func ExportBatch(packages []manifest.Package, opts ExportOptions) (*BatchResult, error) {
+	if err := manifest.Validate(packages); err != nil {
+		return nil, err
+	}
+	if opts.Bundle != "" && opts.Format != "" && !strings.EqualFold(opts.Format, FormatUnityPackage) {
+		return nil, fmt.Errorf("a bundle can only be created in the %s format", FormatUnityPackage)
+	}
+	if opts.UPMDependencies {
+		return nil, fmt.Errorf("UPM dependencies are not supported in batch exports")
+	}
+	run, err := newExportRun(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer run.close()
+
+	roots := make([]*nuget.ResolvedPackage, len(packages))
+	selections := make([]nuget.FrameworkSelection, len(packages))
+	filters := make([]*unity.AssemblyFilter, len(packages))
+	preserves := make([][]unity.Preserve, len(packages))
+	for i, p := range packages {
+		if preserves[i], err = parsePreserves(p.Preserve); err != nil {
+			return nil, fmt.Errorf("%s: %v", p.ID, err)
+		}
+		filters[i] = run.filter
+		if len(p.Exclude) > 0 {
+			deny := append(append([]string{}, opts.DenyAssemblies...), p.Exclude...)
+			filters[i] = unity.NewAssemblyFilter(run.profile, opts.AllowAssemblies, deny)
+		}
+		if err := checkRootDenied(p.ID, filters[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, p := range packages {
+		roots[i], selections[i], err = run.installRoot(p.ID, p.Version, opts.AllowPrerelease || p.Prerelease, p.Framework)
+		if err != nil {
+			return nil, err
+		}
+		roots[i].TargetFramework = selections[i].Target.ShortFolderName()
+	}
+
+	run.resolver.TargetFramework = run.targets[0].ShortFolderName()
+	progress.Infof(run.report, progress.StageResolve, "Resolving dependencies of %d package(s) for %s", len(roots), run.resolver.TargetFramework)
+	graph, err := run.resolver.ResolveGraph(roots)
+	if err != nil {
+		return nil, fmt.Errorf("dependency resolution failed: %w", err)
+	}
+	run.reportGraph(graph)
+
+	result := &BatchResult{Profile: run.profile, Warnings: graph.Warnings}
+	deps, skipped := assignDependencies(graph, filters)
+	for _, pkg := range skipped {
+		progress.Infof(run.report, progress.StageResolve, "Skipping %s %s: excluded by every package that depends on it", pkg.ID, pkg.Version)
+		result.Skipped = append(result.Skipped, pkg.ID)
+	}
+
+	// 先複製所有套件：重複的組件與參照在所有套件之間檢查
+	staged := make([]*stagedPackage, len(packages))
+	for i, p := range packages {
+		if staged[i], err = run.stagePackage(p.ID, roots[i], selections[i], deps[i], filters[i], nil); err != nil {
+			return nil, err
+		}
+		staged[i].preserves = preserves[i]
+		result.Exports = append(result.Exports, staged[i].result)
+	}
+	if err := run.resolveConflicts(staged); err != nil {
+		return nil, err
+	}
+	if err := run.checkReferences(staged); err != nil {
+		return nil, err
+	}
+	warnings, err := run.writeGeneratedFiles(staged)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
+	if opts.Bundle == "" {
+		for _, s := range staged {
+			if err := run.pack(s); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	// 所有套件打包成一個 .unitypackage，各自匯入到 <AssetRoot>/<套件>
+	var dirs []unitypackage.PackageDir
+	for _, s := range staged {
+		dirs = append(dirs, unitypackage.PackageDir{Dir: s.dir, Name: s.name, Options: run.packOptions(s)})
+	}
+	if err := os.MkdirAll(run.outputDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+	bundlePath := filepath.Join(run.outputDir, strings.TrimSuffix(opts.Bundle, ".unitypackage")+".unitypackage")
+	progress.Infof(run.report, progress.StagePack, "Now creating bundle of %d package(s) under %s...", len(dirs), run.assetRoot)
+	if err := unitypackage.CreateUnityPackage(bundlePath, run.assetRoot, dirs); err != nil {
+		return nil, fmt.Errorf("Error creating bundle: %v", err)
+	}
+	for _, export := range result.Exports {
+		export.ArtifactPath = bundlePath
+	}
+	result.ArtifactPath = bundlePath
+	progress.Infof(run.report, progress.StageDone, "Bundle '%s' created successfully!", bundlePath)
+	return result, nil
+}
+
+// assignDependencies 將依賴圖中的每個依賴分配給清單中第一個依賴它、且沒有 exclude 它的 root，
+// 回傳每個 root 要匯出的依賴（依深度排序），以及沒有任何 root 需要的依賴
+func assignDependencies(graph *nuget.DependencyGraph, filters []*unity.AssemblyFilter) ([][]*nuget.ResolvedPackage, []*nuget.ResolvedPackage) {
+	byID := map[string]*nuget.ResolvedPackage{}
+	for _, pkg := range graph.Packages {
+		byID[strings.ToLower(pkg.ID)] = pkg
+	}
+	owner := map[*nuget.ResolvedPackage]int{}
+	for i, root := range graph.Roots {
+		owner[root] = i
+	}
+
+	// 不經過其他 root：root 的依賴由它自己分配，並遵守它的 exclude
+	for i, root := range graph.Roots {
+		visited := map[*nuget.ResolvedPackage]bool{root: true}
+		stack := []*nuget.ResolvedPackage{root}
+		for len(stack) > 0 {
+			pkg := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, dep := range pkg.Dependencies {
+				child, ok := byID[strings.ToLower(dep.ID)]
+				if !ok || visited[child] || filters[i].Excludes(dep.ID) {
+					continue
+				}
+				visited[child] = true
+				if _, owned := owner[child]; owned {
+					if child.Depth == 0 {
+						continue
+					}
+				} else {
+					owner[child] = i
+				}
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	deps := make([][]*nuget.ResolvedPackage, len(graph.Roots))
+	var skipped []*nuget.ResolvedPackage
+	for _, pkg := range graph.Packages[len(graph.Roots):] {
+		if i, ok := owner[pkg]; ok {
+			deps[i] = append(deps[i], pkg)
+		} else {
+			skipped = append(skipped, pkg)
+		}
+	}
+	return deps, skipped
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// testGraph 依 edges 建立依賴圖，roots 為清單中的套件，其餘依 edges 的深度排序
+func testGraph(roots []string, edges map[string][]string) *nuget.DependencyGraph {
+	graph := &nuget.DependencyGraph{}
+	byID := map[string]*nuget.ResolvedPackage{}
+	var queue []*nuget.ResolvedPackage
+	for _, id := range roots {
+		pkg := &nuget.ResolvedPackage{ID: id}
+		byID[id] = pkg
+		graph.Roots = append(graph.Roots, pkg)
+		graph.Packages = append(graph.Packages, pkg)
+		queue = append(queue, pkg)
+	}
+	graph.Root = graph.Roots[0]
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, id := range edges[pkg.ID] {
+			pkg.Dependencies = append(pkg.Dependencies, nuget.Dependency{ID: id})
+			if _, ok := byID[id]; ok {
+				continue
+			}
+			child := &nuget.ResolvedPackage{ID: id, Depth: pkg.Depth + 1}
+			byID[id] = child
+			graph.Packages = append(graph.Packages, child)
+			queue = append(queue, child)
+		}
+	}
+	return graph
+}
+
+func packageIDs(packages []*nuget.ResolvedPackage) []string {
+	ids := []string{}
+	for _, pkg := range packages {
+		ids = append(ids, pkg.ID)
+	}
+	return ids
+}
+
+func TestAssignDependencies(t *testing.T) {
+	edges := map[string][]string{
+		"App.A":  {"Shared", "Only.A"},
+		"App.B":  {"Shared", "App.A", "Only.B"},
+		"Shared": {"Shared.Inner"},
+		"Only.B": {"Excluded.Inner"},
+	}
+	tests := []struct {
+		name     string
+		exclude  [][]string // 每個 root 的 exclude
+		wantDeps [][]string
+		wantSkip []string
+	}{
+		{
+			name:     "shared dependency exported once by the first root",
+			exclude:  [][]string{nil, nil},
+			wantDeps: [][]string{{"Shared", "Only.A", "Shared.Inner"}, {"Only.B", "Excluded.Inner"}},
+			wantSkip: []string{},
+		},
+		{
+			name:     "excluded shared dependency goes to the next root",
+			exclude:  [][]string{{"Shared"}, nil},
+			wantDeps: [][]string{{"Only.A"}, {"Shared", "Only.B", "Shared.Inner", "Excluded.Inner"}},
+			wantSkip: []string{},
+		},
+		{
+			name:     "dependency excluded by every root is skipped",
+			exclude:  [][]string{{"Shared"}, {"Shared", "Excluded.Inner"}},
+			wantDeps: [][]string{{"Only.A"}, {"Only.B"}},
+			wantSkip: []string{"Shared", "Shared.Inner", "Excluded.Inner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := testGraph([]string{"App.A", "App.B"}, edges)
+			filters := make([]*unity.AssemblyFilter, len(tt.exclude))
+			for i, exclude := range tt.exclude {
+				filters[i] = unity.NewAssemblyFilter(unity.DefaultProfile, nil, exclude)
+			}
+			deps, skipped := assignDependencies(graph, filters)
+
+			var got [][]string
+			exported := map[string]int{}
+			for _, d := range deps {
+				got = append(got, packageIDs(d))
+				for _, pkg := range d {
+					exported[pkg.ID]++
+				}
+			}
+			if !reflect.DeepEqual(got, tt.wantDeps) {
+				t.Errorf("deps = %v, want %v", got, tt.wantDeps)
+			}
+			if ids := packageIDs(skipped); !reflect.DeepEqual(ids, tt.wantSkip) {
+				t.Errorf("skipped = %v, want %v", ids, tt.wantSkip)
+			}
+			for id, n := range exported {
+				if n != 1 {
+					t.Errorf("%s exported %d times", id, n)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+// Package cache 為以內容定址的 artifact 快取：key 為匯出參數的雜湊，
+// 每筆資料存放在 Dir/entries 下的獨立目錄，依大小與存放時間上限以 LRU 淘汰。
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entryFile 為每筆資料的描述檔，最後寫入；沒有描述檔的目錄視為未完成而刪除。
+// 描述檔的修改時間記錄最後存取時間，重新啟動後仍能依 LRU 淘汰。
+const entryFile = "entry.json"
+
+// entriesDir 為 Dir 下存放資料的子目錄；Dir 可能是共用的目錄，快取只管理這個子目錄
+const entriesDir = "entries"
+
+// entryDirPattern 為 Put 建立的資料目錄名稱（key 前 16 字元加上 MkdirTemp 的亂數），
+// 只有符合的目錄會在未完成或重複時被刪除
+var entryDirPattern = regexp.MustCompile(`^[0-9a-f]{1,16}-[0-9]+$`)
+
+// ErrTooLarge 表示檔案超過快取的大小上限而未被快取
+var ErrTooLarge = errors.New("artifact is larger than the cache size limit")
+
+// Cache 為快取本身，可同時由多個 goroutine 使用
+type Cache struct {
+	Dir     string
+	MaxSize int64         // 所有檔案的大小上限（bytes），0 為不限
+	MaxAge  time.Duration // 建立後保留的時間上限，0 為不限
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	size    int64
+}
+
+// Entry 描述一筆快取資料
+type Entry struct {
+	Key      string
+	Tag      string // 供選擇性清除的標籤，例如套件 id
+	Name     string // 檔名
+	Size     int64
+	Created  time.Time
+	Accessed time.Time `json:"-"`
+	Meta     json.RawMessage
+}
+
+type entry struct {
+	Entry
+	dir     string
+	refs    int
+	removed bool
+}
+
+// Item 為取出的快取資料。使用期間不會被淘汰或清除，用畢必須呼叫 Release。
+type Item struct {
+	Entry
+	Path string
+
+	release func()
+}
+
+// Release 釋放 Item，可重複呼叫
+func (it *Item) Release() {
+	if it.release != nil {
+		it.release()
+	}
+}
+
+// Key 回傳 v 的 JSON 表示的 SHA-256，做為快取 key
+func Key(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Open 開啟 dir 下的快取並載入既有資料，必要時立即淘汰超過上限的資料。
+// 只會刪除 dir/entries 下由 Put 建立的目錄，dir 中的其他檔案不受影響
+func Open(dir string, maxSize int64, maxAge time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Join(dir, entriesDir), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	c := &Cache{Dir: dir, MaxSize: maxSize, MaxAge: maxAge, entries: map[string]*entry{}}
+
+	items, err := os.ReadDir(filepath.Join(dir, entriesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %v", err)
+	}
+	for _, item := range items {
+		if !item.IsDir() || !entryDirPattern.MatchString(item.Name()) {
+			continue
+		}
+		entryDir := filepath.Join(dir, entriesDir, item.Name())
+		e, err := loadEntry(entryDir)
+		if err != nil {
+			os.RemoveAll(entryDir)
+			continue
+		}
+		if old, ok := c.entries[e.Key]; ok {
+			// 同一 key 只保留較新的一筆
+			if old.Created.After(e.Created) {
+				os.RemoveAll(entryDir)
+				continue
+			}
+			c.remove(old)
+		}
+		c.entries[e.Key] = e
+		c.size += e.Size
+	}
+
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+	return c, nil
+}
+
+func loadEntry(dir string) (*entry, error) {
+	path := filepath.Join(dir, entryFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &entry{dir: dir}
+	if err := json.Unmarshal(data, &e.Entry); err != nil {
+		return nil, err
+	}
+	if e.Key == "" || e.Name == "" {
+		return nil, fmt.Errorf("invalid cache entry %s", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, e.Name)); err != nil {
+		return nil, err
+	}
+	e.Accessed = info.ModTime()
+	return e, nil
+}
+
+// Get 取出 key 的資料並更新存取時間；meta 不為 nil 時解出 Put 時存入的 meta。
+// 不存在或已過期時回傳 false。
+func (c *Cache) Get(key string, meta interface{}) (*Item, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.expired(e, time.Now()) {
+		c.remove(e)
+		return nil, false
+	}
+	if meta != nil && len(e.Meta) > 0 {
+		if err := json.Unmarshal(e.Meta, meta); err != nil {
+			c.remove(e)
+			return nil, false
+		}
+	}
+	e.Accessed = time.Now()
+	os.Chtimes(filepath.Join(e.dir, entryFile), e.Accessed, e.Accessed)
+	return c.acquire(e), true
+}
+
+// Put 將 src 移入快取（不同檔案系統時複製）並回傳已取出的 Item；
+// 同一 key 已有資料時取代之。超過大小上限時回傳 ErrTooLarge，src 保持不動。
+func (c *Cache) Put(key, tag, src string, meta interface{}) (*Item, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaxSize > 0 && info.Size() > c.MaxSize {
+		return nil, ErrTooLarge
+	}
+	var metaData json.RawMessage
+	if meta != nil {
+		if metaData, err = json.Marshal(meta); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := key
+	if len(prefix) > 16 {
+		prefix = prefix[:16]
+	}
+	dir, err := os.MkdirTemp(filepath.Join(c.Dir, entriesDir), prefix+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache entry: %v", err)
+	}
+	now := time.Now()
+	e := &entry{
+		Entry: Entry{Key: key, Tag: tag, Name: filepath.Base(src), Size: info.Size(), Created: now, Accessed: now, Meta: metaData},
+		dir:   dir,
+	}
+	if err := moveFile(src, filepath.Join(dir, e.Name)); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to store %s in cache: %v", src, err)
+	}
+	data, err := json.MarshalIndent(e.Entry, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(dir, entryFile), data)
+	}
+	if err != nil {
+		// 檔案已移入快取，移回原處讓呼叫端仍可使用
+		moveFile(filepath.Join(dir, e.Name), src)
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write cache entry: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.entries[key]; ok {
+		c.remove(old)
+	}
+	c.entries[key] = e
+	c.size += e.Size
+	item := c.acquire(e)
+	c.evict()
+	return item, nil
+}
+
+// Purge 清除標籤為 tag 的資料，tag 為空字串時清除全部，回傳清除的筆數。
+// 使用中的資料在釋放後才刪除檔案。
+func (c *Cache) Purge(tag string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for _, e := range c.entries {
+		if tag == "" || strings.EqualFold(e.Tag, tag) {
+			c.remove(e)
+			count++
+		}
+	}
+	return count
+}
+
+// Stats 回傳目前的資料筆數與總大小
+func (c *Cache) Stats() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries), c.size
+}
+
+// acquire 增加 e 的參照並包成 Item，呼叫時須持有 c.mu
+func (c *Cache) acquire(e *entry) *Item {
+	e.refs++
+	var once sync.Once
+	return &Item{
+		Entry: e.Entry,
+		Path:  filepath.Join(e.dir, e.Name),
+		release: func() {
+			once.Do(func() {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				e.refs--
+				if e.refs == 0 && e.removed {
+					os.RemoveAll(e.dir)
+				}
+			})
+		},
+	}
+}
+
+// remove 將 e 移出快取；沒有使用者時立即刪除檔案。呼叫時須持有 c.mu
+func (c *Cache) remove(e *entry) {
+	if c.entries[e.Key] == e {
+		delete(c.entries, e.Key)
+	}
+	if !e.removed {
+		e.removed = true
+		c.size -= e.Size
+	}
+	if e.refs == 0 {
+		os.RemoveAll(e.dir)
+	}
+}
+
+func (c *Cache) expired(e *entry, now time.Time) bool {
+	return c.MaxAge > 0 && now.Sub(e.Created) > c.MaxAge
+}
+
+// evict 移除過期資料，再依最後存取時間由舊到新淘汰，直到總大小不超過上限。
+// 使用中的資料不會被淘汰。呼叫時須持有 c.mu
+func (c *Cache) evict() {
+	now := time.Now()
+	var candidates []*entry
+	for _, e := range c.entries {
+		if e.refs > 0 {
+			continue
+		}
+		if c.expired(e, now) {
+			c.remove(e)
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	if c.MaxSize <= 0 || c.size <= c.MaxSize {
+		return
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Accessed.Before(candidates[j].Accessed) })
+	for _, e := range candidates {
+		if c.size <= c.MaxSize {
+			break
+		}
+		c.remove(e)
+	}
+}
+
+// moveFile 以 rename 移動檔案，跨檔案系統時改為複製後刪除
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	// 共用目錄中不屬於快取的檔案與目錄
+	foreign := []string{"notes.txt", "other/data.bin", filepath.Join(entriesDir, "readme.txt"), filepath.Join(entriesDir, "project", "a.txt")}
+	for _, name := range foreign {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Put 中斷留下、沒有描述檔的資料目錄
+	incomplete := filepath.Join(dir, entriesDir, "0123456789abcdef-42")
+	if err := os.MkdirAll(incomplete, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Open(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range foreign {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was removed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(incomplete); !os.IsNotExist(err) {
+		t.Errorf("incomplete entry %s was not removed", incomplete)
+	}
+	if count, _ := c.Stats(); count != 0 {
+		t.Errorf("loaded %d entries, want 0", count)
+	}
+}
+
+func TestPutReopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Open(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "Foo.unitypackage")
+	if err := os.WriteFile(src, []byte("artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	key, err := Key("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := c.Put(key, "Foo", src, map[string]string{"version": "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Release()
+
+	reopened, err := Open(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta map[string]string
+	got, ok := reopened.Get(key, &meta)
+	if !ok {
+		t.Fatal("entry was not loaded after reopening")
+	}
+	defer got.Release()
+	if meta["version"] != "1.0.0" {
+		t.Errorf("meta = %v", meta)
+	}
+	if data, err := os.ReadFile(got.Path); err != nil || string(data) != "artifact" {
+		t.Errorf("cached file = %q, %v", data, err)
+	}
+	if n := reopened.Purge("foo"); n != 1 {
+		t.Errorf("Purge removed %d entries, want 1", n)
+	}
+}
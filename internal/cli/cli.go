@@ -0,0 +1,225 @@
+// Package cli 為 nuget2unitypackage 的命令列介面：export、inspect、frameworks 與 resolve 子命令，
+// 不帶參數執行時進入互動模式。結束代碼可供 CI 判斷失敗原因。
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// 結束代碼
+const (
+	ExitOK          = 0 // 成功
+	ExitError       = 1 // 其他錯誤
+	ExitUsage       = 2 // 參數錯誤
+	ExitNotFound    = 3 // 找不到套件或符合範圍的版本
+	ExitNoFramework = 4 // 套件沒有目標框架可用的組件
+	ExitMissingRefs = 5 // -strict-references 時匯出的組件參照了未提供的組件
+)
+
+// ErrUsage 表示命令列參數錯誤
+var ErrUsage = errors.New("invalid usage")
+
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string        { return e.msg }
+func (e *usageError) Is(target error) bool { return target == ErrUsage }
+
+// usagef 回傳屬於 ErrUsage 的錯誤
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// ExitCode 回傳 err 對應的結束代碼
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage), errors.Is(err, internal.ErrDeniedPackage):
+		return ExitUsage
+	case errors.Is(err, nuget.ErrPackageNotFound), errors.Is(err, nuget.ErrVersionNotFound):
+		return ExitNotFound
+	case errors.Is(err, nuget.ErrNoCompatibleFramework):
+		return ExitNoFramework
+	case errors.Is(err, internal.ErrMissingReferences):
+		return ExitMissingRefs
+	default:
+		return ExitError
+	}
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = []command{
+	{"export", "<id>", "convert a NuGet package to a .unitypackage, UPM tarball or package folder", runExport},
+	{"batch", "<manifest>", "export every package listed in a manifest file, sharing their dependencies", runBatch},
+	{"inspect", "<id>", "show the metadata, frameworks, runtimes and dependencies of a package", runInspect},
+	{"frameworks", "<id>", "list the target frameworks of a package and the one selected for Unity", runFrameworks},
+	{"resolve", "<id>", "show the dependency graph an export would use", runResolve},
+}
+
+// env 為命令執行時的輸出、程式名稱與執行中的子命令
+type env struct {
+	name    string
+	command command
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// Run 執行命令列並回傳結束代碼；args 不含程式名稱，為空時進入互動模式
+func Run(args []string, stdout, stderr io.Writer) int {
+	e := &env{name: filepath.Base(os.Args[0]), stdout: stdout, stderr: stderr}
+	if len(args) == 0 {
+		return e.exit(runInteractive(e))
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok {
+				e.command = cmd
+				cmd.run(e, []string{"-h"})
+				return ExitOK
+			}
+		}
+		e.usage(stdout)
+		return ExitOK
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(stderr, "Error: unknown command %q\n\n", name)
+		e.usage(stderr)
+		return ExitUsage
+	}
+	e.command = cmd
+	return e.exit(cmd.run(e, args[1:]))
+}
+
+func (e *env) exit(err error) int {
+	if err == nil || err == flag.ErrHelp {
+		return ExitOK
+	}
+	fmt.Fprintf(e.stderr, "Error: %v\n", err)
+	return ExitCode(err)
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func (e *env) usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [arguments]\n\nCommands:\n", e.name)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-22s %s\n", cmd.name+" "+cmd.args, cmd.summary)
+	}
+	fmt.Fprintf(w, "  %-22s %s\n", "help [command]", "show help for a command")
+	fmt.Fprintf(w, "\nRun %s without arguments for interactive mode.\n", e.name)
+	fmt.Fprintf(w, "\nExit codes: %d success, %d error, %d invalid usage, %d package or version not found, %d no compatible framework, %d missing assembly references\n",
+		ExitOK, ExitError, ExitUsage, ExitNotFound, ExitNoFramework, ExitMissingRefs)
+}
+
+// newFlagSet 建立 cmd 的 FlagSet，錯誤與說明寫到 stderr
+func (e *env) newFlagSet(cmd string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: %s %s %s [flags]\n\n%s\n\nFlags:\n", e.name, cmd, e.command.args, e.command.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs 解析旗標，允許旗標出現在位置參數之後（如 "export Newtonsoft.Json --version 13.0.3"），
+// 並檢查位置參數恰為 want 個
+func parseArgs(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error()}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != want {
+		fs.Usage()
+		return nil, usagef("%s expects %d argument(s), got %d", fs.Name(), want, len(positional))
+	}
+	return positional, nil
+}
+
+// packageFlags 為各子命令共用的套件與目標 Unity 參數
+type packageFlags struct {
+	version    string
+	prerelease bool
+	unity      string
+	apiLevel   string
+	json       bool
+}
+
+func (p *packageFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.version, "version", "", "exact version, range ([1.2,2.0)) or floating version (13.0.*); default latest")
+	fs.BoolVar(&p.prerelease, "prerelease", false, "allow prerelease versions")
+	p.registerTarget(fs)
+}
+
+// registerTarget 只註冊目標 Unity 與 -json 參數
+func (p *packageFlags) registerTarget(fs *flag.FlagSet) {
+	fs.StringVar(&p.unity, "unity", "", "target Unity version, e.g. 2021.3 (default "+unity.DefaultProfile.UnityVersion.String()+")")
+	fs.StringVar(&p.apiLevel, "api-level", "", "API compatibility level: netstandard2.0, netstandard2.1 or netframework")
+	fs.BoolVar(&p.json, "json", false, "print the result as JSON")
+}
+
+func (p *packageFlags) profile() (unity.Profile, error) {
+	profile, err := unity.ParseProfile(p.unity, p.apiLevel)
+	if err != nil {
+		return profile, &usageError{msg: err.Error()}
+	}
+	if profile.IsZero() {
+		profile = unity.DefaultProfile
+	}
+	return profile, nil
+}
+
+func (p *packageFlags) versionRange() (nuget.VersionRange, error) {
+	versionRange, err := nuget.ParseVersionRange(p.version)
+	if err != nil {
+		return versionRange, usagef("invalid package version %q: %v", p.version, err)
+	}
+	return versionRange, nil
+}
+
+// formatList 以逗號連接清單，空清單顯示 "(none)"
+func formatList(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
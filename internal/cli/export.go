@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/manifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/utils"
+)
+
+// runExport 執行 export <id>
+func runExport(e *env, args []string) error {
+	fs := e.newFlagSet("export")
+	var pkg packageFlags
+	pkg.register(fs)
+	framework := fs.String("framework", "", "lib/ folder or target framework to use for the package instead of the automatic choice, e.g. netstandard2.0")
+	var flags exportFlags
+	flags.register(fs)
+
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if _, err := pkg.versionRange(); err != nil {
+		return err
+	}
+	opts, err := flags.options(e, &pkg)
+	if err != nil {
+		return err
+	}
+	opts.PackageID = positional[0]
+	opts.VersionRange = pkg.version
+	opts.AllowPrerelease = pkg.prerelease
+	opts.Framework = *framework
+
+	result, err := internal.Export(opts)
+	if err != nil {
+		return err
+	}
+	if pkg.json {
+		return writeJSON(e.stdout, newExportOutput(result))
+	}
+	printExportResult(e.stdout, result)
+	return nil
+}
+
+// runBatch 執行 batch <manifest>
+func runBatch(e *env, args []string) error {
+	fs := e.newFlagSet("batch")
+	var pkg packageFlags
+	pkg.registerTarget(fs)
+	prerelease := fs.Bool("prerelease", false, "allow prerelease versions for every package")
+	bundle := fs.String("bundle", "", "pack every package into one <name>.unitypackage instead of one output per package")
+	var flags exportFlags
+	flags.register(fs)
+
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	packages, err := manifest.Load(positional[0])
+	if err != nil {
+		return usagef("%v", err)
+	}
+	opts, err := flags.options(e, &pkg)
+	if err != nil {
+		return err
+	}
+	opts.AllowPrerelease = *prerelease
+	opts.Bundle = *bundle
+	if opts.Bundle != "" && !strings.EqualFold(opts.Format, internal.FormatUnityPackage) {
+		return usagef("-bundle requires -format %s", internal.FormatUnityPackage)
+	}
+
+	result, err := internal.ExportBatch(packages, opts)
+	if err != nil {
+		return err
+	}
+	if pkg.json {
+		output := batchOutput{Profile: result.Profile.String(), Exports: []exportOutput{}, Skipped: []string{}, Warnings: []string{}, Artifact: result.ArtifactPath}
+		for _, export := range result.Exports {
+			output.Exports = append(output.Exports, newExportOutput(export))
+		}
+		output.Skipped = append(output.Skipped, result.Skipped...)
+		output.Warnings = append(output.Warnings, result.Warnings...)
+		return writeJSON(e.stdout, output)
+	}
+	for _, export := range result.Exports {
+		printExportResult(e.stdout, export)
+	}
+	if result.ArtifactPath != "" {
+		fmt.Fprintf(e.stdout, "Bundle: %s\n", result.ArtifactPath)
+	}
+	return nil
+}
+
+// exportFlags 為 export 與 batch 共用的輸出與匯入設定參數
+type exportFlags struct {
+	format            string
+	outputDir         string
+	exportPath        string
+	assetRoot         string
+	allowAssemblies   string
+	denyAssemblies    string
+	previousGUIDs     string
+	platforms         string
+	excludePlatforms  string
+	defineConstraints string
+	explicitReference bool
+	preload           bool
+	skipAnalyzers     bool
+	analyzerLanguage  string
+	strictReferences  bool
+	allowReferences   string
+	linkXML           bool
+	preserve          string
+	asmdef            asmdefFlags
+	quiet             bool
+}
+
+func (f *exportFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "format", internal.FormatUnityPackage, "output format: unitypackage, tgz (UPM package) or folder (package folder with .meta files)")
+	fs.StringVar(&f.outputDir, "out", ".", "directory the output is written to")
+	fs.StringVar(&f.outputDir, "output", ".", "alias of -out")
+	fs.StringVar(&f.exportPath, "export-dir", "./export", "working directory for the unpacked package (not used by -format folder)")
+	fs.StringVar(&f.assetRoot, "asset-root", "Assets", "folder of the Unity project the .unitypackage imports into, e.g. Assets/Plugins/NuGet")
+	fs.StringVar(&f.allowAssemblies, "allow", "", "comma separated assemblies to export even if Unity already provides them")
+	fs.StringVar(&f.denyAssemblies, "deny", "", "comma separated assemblies or packages that are never exported")
+	fs.StringVar(&f.previousGUIDs, "previous", "", "previous .unitypackage, .tgz or Unity project whose asset GUIDs are kept")
+	fs.StringVar(&f.platforms, "platforms", "", "comma separated Unity build targets the DLLs are enabled for (default all)")
+	fs.StringVar(&f.excludePlatforms, "exclude-platforms", "", "comma separated Unity build targets the DLLs are disabled for")
+	fs.StringVar(&f.defineConstraints, "define-constraints", "", "comma separated define constraints for the DLLs")
+	fs.BoolVar(&f.explicitReference, "explicit-reference", false, "only reference the DLLs from asmdefs that list them explicitly")
+	fs.BoolVar(&f.preload, "preload", false, "load the DLLs on startup")
+	fs.BoolVar(&f.skipAnalyzers, "skip-analyzers", false, "do not export Roslyn analyzers and source generators")
+	fs.StringVar(&f.analyzerLanguage, "analyzer-language", "cs", "language of the exported Roslyn analyzers: cs or vb")
+	fs.BoolVar(&f.strictReferences, "strict-references", false, "fail when an exported assembly references an assembly that is neither exported nor provided by Unity")
+	fs.StringVar(&f.allowReferences, "allow-references", "", "comma separated assembly references that are provided some other way (a trailing * matches a prefix)")
+	fs.BoolVar(&f.linkXML, "link-xml", false, "write a link.xml that keeps known reflection-heavy assemblies from being stripped by IL2CPP")
+	fs.StringVar(&f.preserve, "preserve", "", "comma separated assemblies (or assembly/namespace) to keep in the link.xml, * for every exported assembly")
+	f.asmdef.register(fs)
+	fs.BoolVar(&f.quiet, "quiet", false, "do not print progress")
+}
+
+// options 檢查參數並建立 ExportOptions（不含套件與版本）
+func (f *exportFlags) options(e *env, pkg *packageFlags) (internal.ExportOptions, error) {
+	profile, err := pkg.profile()
+	if err != nil {
+		return internal.ExportOptions{}, err
+	}
+	switch strings.ToLower(f.format) {
+	case internal.FormatUnityPackage, internal.FormatTarball, internal.FormatFolder:
+	default:
+		return internal.ExportOptions{}, usagef("invalid format %q: use %s, %s or %s", f.format, internal.FormatUnityPackage, internal.FormatTarball, internal.FormatFolder)
+	}
+	if _, err := unitypackage.NormalizeAssetRoot(f.assetRoot); err != nil {
+		return internal.ExportOptions{}, &usageError{msg: err.Error()}
+	}
+	pluginSettings, err := unitypackage.NewPluginSettings(utils.SplitList(f.platforms), utils.SplitList(f.excludePlatforms))
+	if err != nil {
+		return internal.ExportOptions{}, &usageError{msg: err.Error()}
+	}
+	pluginSettings.DefineConstraints = utils.SplitList(f.defineConstraints)
+	pluginSettings.IsExplicitlyReferenced = f.explicitReference
+	pluginSettings.IsPreloaded = f.preload
+	asmdefSettings, err := f.asmdef.settings()
+	if err != nil {
+		return internal.ExportOptions{}, &usageError{msg: err.Error()}
+	}
+
+	// -json 時 stdout 只輸出結果，進度改寫到 stderr
+	var reporter progress.Reporter = progress.Discard
+	if !f.quiet {
+		reporter = e.console(pkg.json)
+	}
+	return internal.ExportOptions{
+		ExportPath:       f.exportPath,
+		Profile:          profile,
+		AllowAssemblies:  utils.SplitList(f.allowAssemblies),
+		DenyAssemblies:   utils.SplitList(f.denyAssemblies),
+		PreviousGUIDs:    f.previousGUIDs,
+		PluginSettings:   &pluginSettings,
+		Asmdef:           &asmdefSettings,
+		SkipAnalyzers:    f.skipAnalyzers,
+		AnalyzerLanguage: f.analyzerLanguage,
+		StrictReferences: f.strictReferences,
+		AllowReferences:  utils.SplitList(f.allowReferences),
+		LinkXML:          f.linkXML,
+		Preserve:         utils.SplitList(f.preserve),
+		Format:           f.format,
+		OutputDir:        f.outputDir,
+		AssetRoot:        f.assetRoot,
+		Progress:         reporter,
+	}, nil
+}
+
+// asmdefFlags 為匯出的 asmdef 的設定參數
+type asmdefFlags struct {
+	platforms          string
+	excludePlatforms   string
+	defineConstraints  string
+	versionDefines     string
+	allowUnsafeCode    bool
+	noEngineReferences bool
+}
+
+func (f *asmdefFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.platforms, "asmdef-platforms", "", "comma separated platforms the asmdef is included on, e.g. Editor,WindowsStandalone64 (default all)")
+	fs.StringVar(&f.excludePlatforms, "asmdef-exclude-platforms", "", "comma separated platforms the asmdef is excluded from")
+	fs.StringVar(&f.defineConstraints, "asmdef-define-constraints", "", "comma separated define constraints of the asmdef")
+	fs.StringVar(&f.versionDefines, "asmdef-version-defines", "", "semicolon separated version defines of the asmdef as <package>:<expression>:<define>")
+	fs.BoolVar(&f.allowUnsafeCode, "allow-unsafe-code", false, "set allowUnsafeCode in the asmdef")
+	fs.BoolVar(&f.noEngineReferences, "no-engine-references", false, "set noEngineReferences in the asmdef")
+}
+
+// settings 檢查參數並建立 AsmdefSettings
+func (f *asmdefFlags) settings() (packagemanifest.AsmdefSettings, error) {
+	settings, err := packagemanifest.NewAsmdefSettings(utils.SplitList(f.platforms), utils.SplitList(f.excludePlatforms))
+	if err != nil {
+		return settings, err
+	}
+	settings.DefineConstraints = utils.SplitList(f.defineConstraints)
+	settings.VersionDefines, err = packagemanifest.ParseVersionDefines(f.versionDefines)
+	settings.AllowUnsafeCode = f.allowUnsafeCode
+	settings.NoEngineReferences = f.noEngineReferences
+	return settings, err
+}
+
+// runInteractive 以提示輸入套件名稱與版本，匯出至目前目錄
+func runInteractive(e *env) error {
+	fmt.Fprintln(e.stdout, "Welcome to the Interactive NuGet to Unity Package Exporter!")
+	fmt.Fprintf(e.stdout, "Run %s help to see the non-interactive commands.\n", e.name)
+	nugetPackageName := utils.GetUserInput("Enter the NuGet package name (e.g. Newtonsoft.Json)", "")
+	if nugetPackageName == "" {
+		return usagef("a package name is required")
+	}
+	packageVersion := utils.GetUserInput("Enter the package version or range, e.g. 13.0.1, [1.2,2.0), 13.0.* (or leave empty for latest)", "")
+	allowPrerelease := utils.GetUserInput("Allow prerelease versions? (y/N)", "n")
+
+	result, err := internal.Export(internal.ExportOptions{
+		PackageID:       nugetPackageName,
+		VersionRange:    packageVersion,
+		AllowPrerelease: strings.EqualFold(allowPrerelease, "y"),
+		ExportPath:      "./export",
+		Progress:        e.console(false),
+	})
+	if err != nil {
+		return err
+	}
+	printExportResult(e.stdout, result)
+	return nil
+}
+
+// console 回傳顯示進度的 Reporter，toStderr 時寫到 stderr
+func (e *env) console(toStderr bool) progress.Reporter {
+	w := e.stdout
+	if toStderr {
+		w = e.stderr
+	}
+	if f, ok := w.(*os.File); ok {
+		return progress.NewTerminal(f)
+	}
+	return progress.NewConsole(w)
+}
+
+// exportOutput 為 export -json 輸出的結果
+type exportOutput struct {
+	ID              string          `json:"id"`
+	Version         string          `json:"version"`
+	Profile         string          `json:"profile"`
+	Framework       string          `json:"framework,omitempty"`
+	FrameworkReason string          `json:"frameworkReason,omitempty"`
+	Packages        []packageOutput `json:"packages"`
+	Warnings        []string        `json:"warnings"`
+	Files           []string        `json:"files"`
+	Assemblies      []assemblyInfo  `json:"assemblies"`
+	Conflicts       []conflict      `json:"conflicts"`
+	MissingRefs     []missingRef    `json:"missingReferences"`
+	Asmdef          string          `json:"asmdef,omitempty"`
+	LinkXML         string          `json:"linkXml,omitempty"`
+	Preserved       []string        `json:"preserved,omitempty"` // link.xml 保留的組件或 "組件/命名空間"
+	Artifact        string          `json:"artifact"`
+}
+
+// conflict 為多個套件帶入同一組件時的處理結果
+type conflict struct {
+	Name                 string           `json:"name"`
+	Kept                 assemblySource   `json:"kept"`
+	Dropped              []assemblySource `json:"dropped"`
+	StrongNameReferences []string         `json:"strongNameReferences,omitempty"`
+}
+
+type assemblySource struct {
+	Package string `json:"package"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+func newAssemblySource(s internal.AssemblySource) assemblySource {
+	return assemblySource{Package: s.Package, Version: s.Version.String(), Path: s.Path}
+}
+
+// missingRef 為匯出組件中無法滿足的參照
+type missingRef struct {
+	Assembly string `json:"assembly"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Exported string `json:"exported,omitempty"` // 只匯出了較低的版本
+}
+
+// batchOutput 為 batch -json 輸出的結果
+type batchOutput struct {
+	Profile  string         `json:"profile"`
+	Exports  []exportOutput `json:"exports"`
+	Skipped  []string       `json:"skipped"`
+	Warnings []string       `json:"warnings"`
+	Artifact string         `json:"artifact,omitempty"` // -bundle 的 .unitypackage
+}
+
+type packageOutput struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Framework string `json:"framework,omitempty"`
+	Copied    int    `json:"copied"`
+	Native    int    `json:"native"`
+	Analyzers int    `json:"analyzers"`
+}
+
+func newExportOutput(result *internal.ExportResult) exportOutput {
+	output := exportOutput{
+		ID:              result.PackageID,
+		Version:         result.Version,
+		Profile:         result.Profile.String(),
+		Framework:       result.Framework.Folder,
+		FrameworkReason: result.Framework.Reason,
+		Packages:        []packageOutput{},
+		Warnings:        []string{},
+		Files:           []string{},
+		Assemblies:      []assemblyInfo{},
+		Conflicts:       []conflict{},
+		MissingRefs:     []missingRef{},
+		Asmdef:          result.Asmdef,
+		LinkXML:         result.LinkXML,
+		Artifact:        result.ArtifactPath,
+	}
+	for _, p := range result.Preserved {
+		output.Preserved = append(output.Preserved, p.String())
+	}
+	for _, p := range result.Packages {
+		output.Packages = append(output.Packages, packageOutput(p))
+	}
+	output.Warnings = append(output.Warnings, result.Warnings...)
+	output.Files = append(output.Files, result.Files...)
+	for _, assembly := range result.Assemblies {
+		output.Assemblies = append(output.Assemblies, newAssemblyInfo(assembly.Path, &assembly.Assembly))
+	}
+	for _, c := range result.Conflicts {
+		out := conflict{Name: c.Name, Kept: newAssemblySource(c.Kept), StrongNameReferences: c.StrongNameReferences}
+		for _, dropped := range c.Dropped {
+			out.Dropped = append(out.Dropped, newAssemblySource(dropped))
+		}
+		output.Conflicts = append(output.Conflicts, out)
+	}
+	for _, missing := range result.MissingReferences {
+		output.MissingRefs = append(output.MissingRefs, missingRef{Assembly: missing.Assembly, Name: missing.Reference.Name, Version: missing.Reference.Version.String(), Exported: missing.Exported})
+	}
+	return output
+}
+
+func printExportResult(w io.Writer, result *internal.ExportResult) {
+	fmt.Fprintf(w, "Exported %s %s for %s using %s: %s\n", result.PackageID, result.Version, result.Profile, result.Framework.Folder, result.Framework.Reason)
+	fmt.Fprintf(w, "Output: %s\n", result.ArtifactPath)
+	fmt.Fprintln(w, "Done.")
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,463 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/utils"
+)
+
+type inspectResult struct {
+	ID           string            `json:"id"`
+	Version      string            `json:"version"`
+	Authors      string            `json:"authors,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Frameworks   []string          `json:"frameworks"`
+	Runtimes     []runtimeInfo     `json:"runtimes"`
+	Analyzers    []string          `json:"analyzers"`
+	Assemblies   []assemblyInfo    `json:"assemblies"`
+	Dependencies []dependencyGroup `json:"dependencies"`
+}
+
+// assemblyInfo 為從 DLL metadata 讀出的組件資訊
+type assemblyInfo struct {
+	Path              string   `json:"path"`
+	Name              string   `json:"name"`
+	Version           string   `json:"version"`
+	Culture           string   `json:"culture,omitempty"`
+	PublicKeyToken    string   `json:"publicKeyToken,omitempty"`
+	TargetFramework   string   `json:"targetFramework,omitempty"`
+	ReferenceAssembly bool     `json:"referenceAssembly"`
+	References        []string `json:"references"`
+}
+
+func newAssemblyInfo(path string, assembly *clrmeta.Assembly) assemblyInfo {
+	info := assemblyInfo{
+		Path:              path,
+		Name:              assembly.Name,
+		Version:           assembly.Version.String(),
+		Culture:           assembly.Culture,
+		PublicKeyToken:    assembly.PublicKeyToken,
+		TargetFramework:   assembly.TargetFramework,
+		ReferenceAssembly: assembly.IsReferenceAssembly,
+		References:        []string{},
+	}
+	for _, ref := range assembly.References {
+		info.References = append(info.References, ref.String())
+	}
+	return info
+}
+
+type runtimeInfo struct {
+	RID        string   `json:"rid"`
+	Native     []string `json:"native,omitempty"`
+	Frameworks []string `json:"frameworks,omitempty"`
+}
+
+type dependencyGroup struct {
+	TargetFramework string             `json:"targetFramework,omitempty"`
+	Dependencies    []nuget.Dependency `json:"dependencies"`
+}
+
+type frameworkInfo struct {
+	Folder     string `json:"folder"`
+	Framework  string `json:"framework,omitempty"`
+	Compatible bool   `json:"compatible"`
+	Selected   bool   `json:"selected"`
+}
+
+type frameworksResult struct {
+	ID         string          `json:"id"`
+	Version    string          `json:"version"`
+	Profile    string          `json:"profile"`
+	Frameworks []frameworkInfo `json:"frameworks"`
+	Selected   string          `json:"selected,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+}
+
+type resolvedPackage struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Depth     int    `json:"depth"`
+	Framework string `json:"framework,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type resolveResult struct {
+	Profile  string            `json:"profile"`
+	Packages []resolvedPackage `json:"packages"`
+	Excluded []string          `json:"excluded"`
+	Warnings []string          `json:"warnings"`
+}
+
+// session 為下載套件的暫存目錄與 resolver
+type session struct {
+	resolver *nuget.Resolver
+	tempDir  string
+}
+
+// install 解析 pkg 的版本並下載 root 套件
+func install(id string, pkg packageFlags) (*session, *nuget.ResolvedPackage, error) {
+	versionRange, err := pkg.versionRange()
+	if err != nil {
+		return nil, nil, err
+	}
+	client := nuget.DefaultClient()
+	version, err := client.ResolveVersion(id, versionRange, pkg.prerelease)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "nuget_temp")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	s := &session{resolver: nuget.NewResolver(client, tempDir), tempDir: tempDir}
+	s.resolver.AllowPrerelease = pkg.prerelease
+	root, err := s.resolver.Install(id, version)
+	if err != nil {
+		s.close()
+		return nil, nil, err
+	}
+	return s, root, nil
+}
+
+func (s *session) close() {
+	os.RemoveAll(s.tempDir)
+}
+
+// runInspect 執行 inspect <id>
+func runInspect(e *env, args []string) error {
+	flags := e.newFlagSet("inspect")
+	var pkg packageFlags
+	pkg.register(flags)
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	s, root, err := install(positional[0], pkg)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	result := inspectResult{
+		ID:           root.ID,
+		Version:      root.Version.String(),
+		Authors:      root.Nuspec.Metadata.Authors,
+		Description:  strings.TrimSpace(root.Nuspec.Metadata.Description),
+		Runtimes:     []runtimeInfo{},
+		Analyzers:    []string{},
+		Assemblies:   []assemblyInfo{},
+		Dependencies: []dependencyGroup{},
+	}
+	if result.Frameworks, err = nuget.ListFrameworks(root.InstallDir); err != nil {
+		return err
+	}
+	runtimes, err := nuget.ListRuntimeAssets(root.InstallDir)
+	if err != nil {
+		return err
+	}
+	for _, runtime := range runtimes {
+		result.Runtimes = append(result.Runtimes, runtimeInfo{RID: runtime.RID, Native: runtime.NativeFiles, Frameworks: runtime.Frameworks})
+	}
+	if result.Analyzers, err = listAnalyzers(root.InstallDir); err != nil {
+		return err
+	}
+	if result.Assemblies, err = listAssemblies(root.InstallDir); err != nil {
+		return err
+	}
+	deps := root.Nuspec.Metadata.Dependencies
+	if len(deps.Dependencies) > 0 {
+		result.Dependencies = append(result.Dependencies, dependencyGroup{Dependencies: deps.Dependencies})
+	}
+	for _, group := range deps.Groups {
+		result.Dependencies = append(result.Dependencies, dependencyGroup{TargetFramework: group.TargetFramework, Dependencies: group.Dependencies})
+	}
+
+	if pkg.json {
+		return writeJSON(e.stdout, result)
+	}
+	w := e.stdout
+	fmt.Fprintf(w, "%s %s\n", result.ID, result.Version)
+	if result.Authors != "" {
+		fmt.Fprintf(w, "Authors: %s\n", result.Authors)
+	}
+	if result.Description != "" {
+		fmt.Fprintf(w, "Description: %s\n", result.Description)
+	}
+	fmt.Fprintf(w, "Frameworks: %s\n", formatList(result.Frameworks))
+	fmt.Fprintln(w, "Runtimes:")
+	if len(result.Runtimes) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, runtime := range result.Runtimes {
+		fmt.Fprintf(w, "  %s: native %s; lib %s\n", runtime.RID, formatList(runtime.Native), formatList(runtime.Frameworks))
+	}
+	fmt.Fprintf(w, "Analyzers: %s\n", formatList(result.Analyzers))
+	fmt.Fprintln(w, "Assemblies:")
+	if len(result.Assemblies) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, assembly := range result.Assemblies {
+		fmt.Fprintf(w, "  %s: %s, Version=%s", assembly.Path, assembly.Name, assembly.Version)
+		if assembly.TargetFramework != "" {
+			fmt.Fprintf(w, " (%s)", assembly.TargetFramework)
+		}
+		if assembly.ReferenceAssembly {
+			fmt.Fprint(w, " [reference assembly]")
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "Dependencies:")
+	if len(result.Dependencies) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, group := range result.Dependencies {
+		name := group.TargetFramework
+		if name == "" {
+			name = "(all frameworks)"
+		}
+		items := make([]string, len(group.Dependencies))
+		for i, dep := range group.Dependencies {
+			items[i] = strings.TrimSpace(dep.ID + " " + dep.Version)
+		}
+		fmt.Fprintf(w, "  %s: %s\n", name, formatList(items))
+	}
+	return nil
+}
+
+// listAnalyzers 列出 analyzers/ 下的 DLL（"/" 分隔的相對路徑）
+func listAnalyzers(installDir string) ([]string, error) {
+	dir := filepath.Join(installDir, "analyzers")
+	analyzers := []string{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return analyzers, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".dll") {
+			return err
+		}
+		rel, err := filepath.Rel(installDir, path)
+		if err != nil {
+			return err
+		}
+		analyzers = append(analyzers, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(analyzers)
+	return analyzers, err
+}
+
+// listAssemblies 讀取 lib/ 與 runtimes/<rid>/lib/ 下每個 .NET 組件的 metadata，略過 native DLL
+func listAssemblies(installDir string) ([]assemblyInfo, error) {
+	assemblies := []assemblyInfo{}
+	dirs, err := filepath.Glob(filepath.Join(installDir, "runtimes", "*", "lib"))
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range append([]string{filepath.Join(installDir, "lib")}, dirs...) {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".dll") {
+				return err
+			}
+			assembly, err := clrmeta.ReadFile(path)
+			if errors.Is(err, clrmeta.ErrNotAssembly) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(installDir, path)
+			if err != nil {
+				return err
+			}
+			assemblies = append(assemblies, newAssemblyInfo(filepath.ToSlash(rel), assembly))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return assemblies, nil
+}
+
+// runFrameworks 執行 frameworks <id>
+func runFrameworks(e *env, args []string) error {
+	flags := e.newFlagSet("frameworks")
+	var pkg packageFlags
+	pkg.register(flags)
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	profile, err := pkg.profile()
+	if err != nil {
+		return err
+	}
+
+	s, root, err := install(positional[0], pkg)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	folders, err := nuget.ListFrameworks(root.InstallDir)
+	if err != nil {
+		return err
+	}
+	targets := nuget.ProfileTargets(profile)
+	result := frameworksResult{ID: root.ID, Version: root.Version.String(), Profile: profile.String(), Frameworks: []frameworkInfo{}}
+	selection, selectErr := nuget.SelectFramework(folders, targets)
+	if selectErr == nil {
+		result.Selected = selection.Folder
+		result.Reason = selection.Reason
+	}
+	for _, folder := range folders {
+		info := frameworkInfo{Folder: folder, Selected: folder == result.Selected}
+		if fw, err := nuget.ParseFramework(folder); err == nil {
+			info.Framework = fw.String()
+			for _, target := range targets {
+				if nuget.IsCompatible(target, fw) {
+					info.Compatible = true
+					break
+				}
+			}
+		}
+		result.Frameworks = append(result.Frameworks, info)
+	}
+
+	if pkg.json {
+		if err := writeJSON(e.stdout, result); err != nil {
+			return err
+		}
+	} else {
+		w := e.stdout
+		fmt.Fprintf(w, "%s %s for %s:\n", result.ID, result.Version, result.Profile)
+		if len(result.Frameworks) == 0 {
+			fmt.Fprintln(w, "  (no lib/ frameworks)")
+		}
+		for _, info := range result.Frameworks {
+			mark := ""
+			switch {
+			case info.Selected:
+				mark = "selected"
+			case info.Compatible:
+				mark = "compatible"
+			}
+			fmt.Fprintf(w, "  %-24s %-28s %s\n", info.Folder, info.Framework, mark)
+		}
+		if result.Selected != "" {
+			fmt.Fprintf(w, "Selected %s: %s\n", result.Selected, result.Reason)
+		}
+	}
+	if len(folders) > 0 && selectErr != nil {
+		return selectErr
+	}
+	return nil
+}
+
+// runResolve 執行 resolve <id>
+func runResolve(e *env, args []string) error {
+	flags := e.newFlagSet("resolve")
+	var pkg packageFlags
+	pkg.register(flags)
+	framework := flags.String("framework", "", "lib/ folder or target framework to use for the package instead of the automatic choice")
+	allowAssemblies := flags.String("allow", "", "comma separated assemblies to export even if Unity already provides them")
+	denyAssemblies := flags.String("deny", "", "comma separated assemblies or packages that are never exported")
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	profile, err := pkg.profile()
+	if err != nil {
+		return err
+	}
+
+	s, root, err := install(positional[0], pkg)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	targets := nuget.ProfileTargets(profile)
+	folders, err := nuget.ListFrameworks(root.InstallDir)
+	if err != nil {
+		return err
+	}
+	selection := nuget.FrameworkSelection{Target: targets[0]}
+	if *framework != "" {
+		selection, err = nuget.FindFramework(folders, *framework)
+	} else if len(folders) > 0 {
+		selection, err = nuget.SelectFramework(folders, targets)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s has no framework usable by %s: %w", root.ID, root.Version, profile, err)
+	}
+
+	s.resolver.Filter = unity.NewAssemblyFilter(profile, utils.SplitList(*allowAssemblies), utils.SplitList(*denyAssemblies))
+	s.resolver.TargetFramework = selection.Target.ShortFolderName()
+	graph, err := s.resolver.ResolveDependencies(root)
+	if err != nil {
+		return fmt.Errorf("dependency resolution failed: %w", err)
+	}
+
+	result := resolveResult{Profile: profile.String(), Packages: []resolvedPackage{}, Excluded: []string{}, Warnings: []string{}}
+	var frameworkErr error
+	for i, p := range graph.Packages {
+		resolved := resolvedPackage{ID: p.ID, Version: p.Version.String(), Depth: p.Depth}
+		if i == 0 {
+			resolved.Framework = selection.Folder
+		} else if dirs, err := nuget.ListFrameworks(p.InstallDir); err != nil {
+			return err
+		} else if len(dirs) > 0 {
+			resolved.Framework, err = nuget.ChooseFramework(dirs, targets)
+			if err != nil {
+				resolved.Error = err.Error()
+				frameworkErr = fmt.Errorf("dependency %s %s: %w", p.ID, p.Version, err)
+			}
+		}
+		result.Packages = append(result.Packages, resolved)
+	}
+	for _, id := range graph.Excluded {
+		result.Excluded = append(result.Excluded, fmt.Sprintf("%s (%s)", id, s.resolver.Filter.Reason(id)))
+	}
+	result.Warnings = append(result.Warnings, graph.Warnings...)
+
+	if pkg.json {
+		if err := writeJSON(e.stdout, result); err != nil {
+			return err
+		}
+		return frameworkErr
+	}
+	w := e.stdout
+	fmt.Fprintf(w, "Dependency graph for %s:\n", result.Profile)
+	for _, p := range result.Packages {
+		detail := p.Framework
+		if p.Error != "" {
+			detail = p.Error
+		} else if detail == "" {
+			detail = "no assemblies"
+		}
+		fmt.Fprintf(w, "%s%s %s (%s)\n", strings.Repeat("  ", p.Depth), p.ID, p.Version, detail)
+	}
+	if len(result.Excluded) > 0 {
+		fmt.Fprintf(w, "Excluded: %s\n", strings.Join(result.Excluded, ", "))
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+	return frameworkErr
+}
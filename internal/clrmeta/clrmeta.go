@@ -0,0 +1,270 @@
+// Package clrmeta 讀取 .NET 組件的 PE 標頭與 ECMA-335 metadata，取得組件名稱、版本、
+// 文化特性、公開金鑰 token、目標框架、參考的組件，以及是否為參考組件 (reference assembly)。
+package clrmeta
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNotAssembly 表示檔案不是 .NET 組件（例如 native DLL）
+var ErrNotAssembly = errors.New("not a .NET assembly")
+
+// Version 為組件版本
+type Version struct {
+	Major, Minor, Build, Revision uint16
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Build, v.Revision)
+}
+
+// Compare 比較兩個版本，回傳 -1、0 或 1
+func (v Version) Compare(other Version) int {
+	a := [4]uint16{v.Major, v.Minor, v.Build, v.Revision}
+	b := [4]uint16{other.Major, other.Minor, other.Build, other.Revision}
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// AssemblyName 為組件的識別
+type AssemblyName struct {
+	Name           string
+	Version        Version
+	Culture        string // 空字串為 neutral
+	PublicKeyToken string // 16 個十六進位字元，沒有強式名稱時為空字串
+}
+
+// String 回傳顯示名稱，例如 "System.Memory, Version=4.0.1.1, Culture=neutral, PublicKeyToken=cc7b13ffcd2ddd51"
+func (n AssemblyName) String() string {
+	culture := n.Culture
+	if culture == "" {
+		culture = "neutral"
+	}
+	token := n.PublicKeyToken
+	if token == "" {
+		token = "null"
+	}
+	return fmt.Sprintf("%s, Version=%s, Culture=%s, PublicKeyToken=%s", n.Name, n.Version, culture, token)
+}
+
+// Assembly 為從組件 metadata 讀出的資訊
+type Assembly struct {
+	AssemblyName
+	RuntimeVersion string // metadata 的 CLR 版本字串，如 "v4.0.30319"
+	// TargetFramework 為 TargetFrameworkAttribute 的框架名稱（如 ".NETStandard,Version=v2.0"），沒有此屬性時為空字串
+	TargetFramework string
+	// TargetFrameworkDisplayName 為 TargetFrameworkAttribute 的 FrameworkDisplayName
+	TargetFrameworkDisplayName string
+	References                 []AssemblyName // AssemblyRef 表
+	IsReferenceAssembly        bool           // 有 ReferenceAssemblyAttribute，只有 API 定義、不能在執行時載入
+}
+
+// ReadFile 讀取 path 的組件 metadata
+func ReadFile(path string) (*Assembly, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	assembly, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return assembly, nil
+}
+
+// Read 從 r 讀取組件 metadata；r 不是 .NET 組件時回傳 ErrNotAssembly
+func Read(r io.ReaderAt) (*Assembly, error) {
+	image, err := readImage(r)
+	if err != nil {
+		return nil, err
+	}
+	// CLI header 位於第 15 個 data directory (IMAGE_DIRECTORY_ENTRY_COM_DESCRIPTOR)
+	if image.cliHeader.rva == 0 || image.cliHeader.size < 16 {
+		return nil, ErrNotAssembly
+	}
+	cor20, err := image.read(image.cliHeader.rva, 16)
+	if err != nil {
+		return nil, err
+	}
+	metadataRVA := binary.LittleEndian.Uint32(cor20[8:])
+	metadataSize := binary.LittleEndian.Uint32(cor20[12:])
+	metadata, err := image.read(metadataRVA, metadataSize)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return m.assembly()
+}
+
+// assembly 由 metadata 表組出 Assembly
+func (m *metadata) assembly() (*Assembly, error) {
+	if m.rows[tableAssembly] == 0 {
+		return nil, fmt.Errorf("%w: the module has no assembly manifest", ErrNotAssembly)
+	}
+	row := m.row(tableAssembly, 1)
+	assembly := &Assembly{RuntimeVersion: m.version}
+	assembly.Version = Version{row.u16(4), row.u16(6), row.u16(8), row.u16(10)}
+	publicKey := m.blob(row.index(16, m.blobSize))
+	assembly.Name = m.string(row.index(16+m.blobSize, m.stringSize))
+	assembly.Culture = m.string(row.index(16+m.blobSize+m.stringSize, m.stringSize))
+	if len(publicKey) > 0 {
+		assembly.PublicKeyToken = publicKeyToken(publicKey)
+	}
+
+	for i := uint32(1); i <= m.rows[tableAssemblyRef]; i++ {
+		ref := m.row(tableAssemblyRef, i)
+		name := AssemblyName{Version: Version{ref.u16(0), ref.u16(2), ref.u16(4), ref.u16(6)}}
+		flags := ref.u32(8)
+		key := m.blob(ref.index(12, m.blobSize))
+		name.Name = m.string(ref.index(12+m.blobSize, m.stringSize))
+		name.Culture = m.string(ref.index(12+m.blobSize+m.stringSize, m.stringSize))
+		switch {
+		case len(key) == 0:
+		case flags&assemblyFlagPublicKey != 0:
+			name.PublicKeyToken = publicKeyToken(key)
+		default:
+			name.PublicKeyToken = hex.EncodeToString(key)
+		}
+		assembly.References = append(assembly.References, name)
+	}
+
+	// 組件層級的 attribute：HasCustomAttribute 的 Assembly tag 為 14
+	for i := uint32(1); i <= m.rows[tableCustomAttribute]; i++ {
+		attr := m.row(tableCustomAttribute, i)
+		parent := attr.index(0, m.size(codedHasCustomAttribute))
+		if parent&0x1f != 14 {
+			continue
+		}
+		ctor := attr.index(m.size(codedHasCustomAttribute), m.size(codedCustomAttributeType))
+		namespace, name := m.attributeType(ctor)
+		switch {
+		case namespace == "System.Runtime.Versioning" && name == "TargetFrameworkAttribute":
+			value := m.blob(attr.index(m.size(codedHasCustomAttribute)+m.size(codedCustomAttributeType), m.blobSize))
+			assembly.TargetFramework, assembly.TargetFrameworkDisplayName = parseTargetFramework(value)
+		case namespace == "System.Runtime.CompilerServices" && name == "ReferenceAssemblyAttribute":
+			assembly.IsReferenceAssembly = true
+		}
+	}
+	return assembly, nil
+}
+
+// assemblyFlagPublicKey 表示 AssemblyRef 存的是完整公開金鑰而不是 token
+const assemblyFlagPublicKey = 0x0001
+
+// publicKeyToken 為公開金鑰 SHA-1 雜湊的最後 8 個位元組（反轉順序）
+func publicKeyToken(key []byte) string {
+	sum := sha1.Sum(key)
+	token := make([]byte, 8)
+	for i := range token {
+		token[i] = sum[len(sum)-1-i]
+	}
+	return hex.EncodeToString(token)
+}
+
+// attributeType 回傳 CustomAttributeType coded index 所指建構函式的型別命名空間與名稱
+func (m *metadata) attributeType(ctor uint32) (namespace, name string) {
+	tag, index := ctor&0x7, ctor>>3
+	switch tag {
+	case 2: // MethodDef：找出方法所屬的 TypeDef
+		for i := m.rows[tableTypeDef]; i >= 1; i-- {
+			typeDef := m.row(tableTypeDef, i)
+			methodList := typeDef.index(4+2*m.stringSize+m.size(codedTypeDefOrRef)+m.tableIndexSize(tableField), m.tableIndexSize(tableMethodDef))
+			if methodList <= index {
+				return m.string(typeDef.index(4+m.stringSize, m.stringSize)), m.string(typeDef.index(4, m.stringSize))
+			}
+		}
+	case 3: // MemberRef：Class 為 MemberRefParent coded index
+		if index == 0 || index > m.rows[tableMemberRef] {
+			return "", ""
+		}
+		parent := m.row(tableMemberRef, index).index(0, m.size(codedMemberRefParent))
+		switch parent & 0x7 {
+		case 0: // TypeDef
+			if i := parent >> 3; i >= 1 && i <= m.rows[tableTypeDef] {
+				typeDef := m.row(tableTypeDef, i)
+				return m.string(typeDef.index(4+m.stringSize, m.stringSize)), m.string(typeDef.index(4, m.stringSize))
+			}
+		case 1: // TypeRef
+			if i := parent >> 3; i >= 1 && i <= m.rows[tableTypeRef] {
+				typeRef := m.row(tableTypeRef, i)
+				scope := m.size(codedResolutionScope)
+				return m.string(typeRef.index(scope+m.stringSize, m.stringSize)), m.string(typeRef.index(scope, m.stringSize))
+			}
+		}
+	}
+	return "", ""
+}
+
+// parseTargetFramework 解析 TargetFrameworkAttribute 的 blob：prolog、框架名稱，以及具名參數 FrameworkDisplayName
+func parseTargetFramework(value []byte) (framework, displayName string) {
+	if len(value) < 2 || binary.LittleEndian.Uint16(value) != 0x0001 {
+		return "", ""
+	}
+	framework, rest, ok := readSerString(value[2:])
+	if !ok || len(rest) < 2 {
+		return framework, ""
+	}
+	count := binary.LittleEndian.Uint16(rest)
+	rest = rest[2:]
+	for i := uint16(0); i < count; i++ {
+		// FIELD (0x53) 或 PROPERTY (0x54)、型別 (string 為 0x0e)、名稱、值
+		if len(rest) < 2 || rest[1] != 0x0e {
+			break
+		}
+		var argName, argValue string
+		if argName, rest, ok = readSerString(rest[2:]); !ok {
+			break
+		}
+		if argValue, rest, ok = readSerString(rest); !ok {
+			break
+		}
+		if argName == "FrameworkDisplayName" {
+			displayName = argValue
+		}
+	}
+	return framework, displayName
+}
+
+// readSerString 讀取 custom attribute blob 中的 SerString（壓縮長度 + UTF-8，0xff 為 null）
+func readSerString(data []byte) (string, []byte, bool) {
+	if len(data) > 0 && data[0] == 0xff {
+		return "", data[1:], true
+	}
+	length, n, ok := compressedUint(data)
+	if !ok || uint32(len(data)-n) < length {
+		return "", nil, false
+	}
+	return strings.ToValidUTF8(string(data[n:n+int(length)]), "?"), data[n+int(length):], true
+}
+
+// compressedUint 解碼 ECMA-335 II.23.2 的壓縮無號整數，回傳值與使用的位元組數
+func compressedUint(data []byte) (uint32, int, bool) {
+	switch {
+	case len(data) >= 1 && data[0]&0x80 == 0:
+		return uint32(data[0]), 1, true
+	case len(data) >= 2 && data[0]&0xc0 == 0x80:
+		return uint32(data[0]&0x3f)<<8 | uint32(data[1]), 2, true
+	case len(data) >= 4 && data[0]&0xe0 == 0xc0:
+		return uint32(data[0]&0x1f)<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]), 4, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,110 @@
+package clrmeta
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// image 為 PE 檔中讀取 metadata 所需的部分。不使用 debug/pe：ReadyToRun 組件的 machine
+// 欄位會與目標作業系統的值 XOR（如 linux-x64 為 0xfd1d），debug/pe 不接受這些值
+type image struct {
+	r         io.ReaderAt
+	cliHeader dataDirectory
+	sections  []section
+}
+
+type dataDirectory struct {
+	rva, size uint32
+}
+
+type section struct {
+	virtualAddress, virtualSize uint32
+	rawOffset, rawSize          uint32
+}
+
+// readImage 讀取 DOS、COFF 與 optional header 以及 section table
+func readImage(r io.ReaderAt) (*image, error) {
+	dos := make([]byte, 64)
+	if _, err := r.ReadAt(dos, 0); err != nil || dos[0] != 'M' || dos[1] != 'Z' {
+		return nil, fmt.Errorf("%w: not a PE file", ErrNotAssembly)
+	}
+	offset := int64(binary.LittleEndian.Uint32(dos[0x3c:]))
+
+	// PE 簽章與 COFF header
+	coff := make([]byte, 24)
+	if _, err := r.ReadAt(coff, offset); err != nil || string(coff[:4]) != "PE\x00\x00" {
+		return nil, fmt.Errorf("%w: not a PE file", ErrNotAssembly)
+	}
+	sectionCount := int(binary.LittleEndian.Uint16(coff[6:]))
+	optionalSize := int64(binary.LittleEndian.Uint16(coff[20:]))
+	offset += 24
+
+	optional := make([]byte, optionalSize)
+	if _, err := r.ReadAt(optional, offset); err != nil || len(optional) < 2 {
+		return nil, fmt.Errorf("invalid assembly: truncated optional header")
+	}
+	// PE32 與 PE32+ 的 data directory 位置不同
+	var directories int
+	switch binary.LittleEndian.Uint16(optional) {
+	case 0x10b:
+		directories = 96
+	case 0x20b:
+		directories = 112
+	default:
+		return nil, fmt.Errorf("%w: unknown optional header", ErrNotAssembly)
+	}
+	img := &image{r: r}
+	if len(optional) >= directories {
+		count := binary.LittleEndian.Uint32(optional[directories-4:])
+		if entry := directories + 14*8; count > 14 && len(optional) >= entry+8 {
+			img.cliHeader = dataDirectory{
+				rva:  binary.LittleEndian.Uint32(optional[entry:]),
+				size: binary.LittleEndian.Uint32(optional[entry+4:]),
+			}
+		}
+	}
+	offset += optionalSize
+
+	headers := make([]byte, 40*sectionCount)
+	if _, err := r.ReadAt(headers, offset); err != nil {
+		return nil, fmt.Errorf("invalid assembly: truncated section table")
+	}
+	for i := 0; i < sectionCount; i++ {
+		header := headers[i*40:]
+		img.sections = append(img.sections, section{
+			virtualSize:    binary.LittleEndian.Uint32(header[8:]),
+			virtualAddress: binary.LittleEndian.Uint32(header[12:]),
+			rawSize:        binary.LittleEndian.Uint32(header[16:]),
+			rawOffset:      binary.LittleEndian.Uint32(header[20:]),
+		})
+	}
+	return img, nil
+}
+
+// read 讀取相對虛擬位址 rva 起 size 個位元組
+func (img *image) read(rva, size uint32) ([]byte, error) {
+	for _, s := range img.sections {
+		length := s.virtualSize
+		if length < s.rawSize {
+			length = s.rawSize
+		}
+		if rva < s.virtualAddress || rva-s.virtualAddress >= length {
+			continue
+		}
+		offset := rva - s.virtualAddress
+		if uint64(offset)+uint64(size) > uint64(s.rawSize) {
+			return nil, fmt.Errorf("invalid assembly: data at RVA 0x%x exceeds its section", rva)
+		}
+		// section header 的大小不可信，依實際讀到的資料配置記憶體，避免損毀的檔案配置數 GB
+		data, err := io.ReadAll(io.NewSectionReader(img.r, int64(s.rawOffset)+int64(offset), int64(size)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid assembly: %v", err)
+		}
+		if uint32(len(data)) < size {
+			return nil, fmt.Errorf("invalid assembly: data at RVA 0x%x is truncated", rva)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("invalid assembly: RVA 0x%x is outside every section", rva)
+}
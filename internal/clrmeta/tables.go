@@ -0,0 +1,369 @@
+package clrmeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// metadata 表的編號（ECMA-335 II.22）
+const (
+	tableModule                 = 0x00
+	tableTypeRef                = 0x01
+	tableTypeDef                = 0x02
+	tableFieldPtr               = 0x03
+	tableField                  = 0x04
+	tableMethodPtr              = 0x05
+	tableMethodDef              = 0x06
+	tableParamPtr               = 0x07
+	tableParam                  = 0x08
+	tableInterfaceImpl          = 0x09
+	tableMemberRef              = 0x0a
+	tableConstant               = 0x0b
+	tableCustomAttribute        = 0x0c
+	tableFieldMarshal           = 0x0d
+	tableDeclSecurity           = 0x0e
+	tableClassLayout            = 0x0f
+	tableFieldLayout            = 0x10
+	tableStandAloneSig          = 0x11
+	tableEventMap               = 0x12
+	tableEventPtr               = 0x13
+	tableEvent                  = 0x14
+	tablePropertyMap            = 0x15
+	tablePropertyPtr            = 0x16
+	tableProperty               = 0x17
+	tableMethodSemantics        = 0x18
+	tableMethodImpl             = 0x19
+	tableModuleRef              = 0x1a
+	tableTypeSpec               = 0x1b
+	tableImplMap                = 0x1c
+	tableFieldRVA               = 0x1d
+	tableEncLog                 = 0x1e
+	tableEncMap                 = 0x1f
+	tableAssembly               = 0x20
+	tableAssemblyProcessor      = 0x21
+	tableAssemblyOS             = 0x22
+	tableAssemblyRef            = 0x23
+	tableAssemblyRefProcessor   = 0x24
+	tableAssemblyRefOS          = 0x25
+	tableFile                   = 0x26
+	tableExportedType           = 0x27
+	tableManifestResource       = 0x28
+	tableNestedClass            = 0x29
+	tableGenericParam           = 0x2a
+	tableMethodSpec             = 0x2b
+	tableGenericParamConstraint = 0x2c
+
+	tableCount = 64
+)
+
+// coded index 的種類（ECMA-335 II.24.2.6）
+const (
+	codedTypeDefOrRef = iota
+	codedHasConstant
+	codedHasCustomAttribute
+	codedHasFieldMarshal
+	codedHasDeclSecurity
+	codedMemberRefParent
+	codedHasSemantics
+	codedMethodDefOrRef
+	codedMemberForwarded
+	codedImplementation
+	codedCustomAttributeType
+	codedResolutionScope
+	codedTypeOrMethodDef
+)
+
+// unusedTable 為 coded index 中沒有對應表的 tag
+const unusedTable = -1
+
+// codedTables 為每種 coded index 的 tag 依序對應的表
+var codedTables = [][]int{
+	codedTypeDefOrRef: {tableTypeDef, tableTypeRef, tableTypeSpec},
+	codedHasConstant:  {tableField, tableParam, tableProperty},
+	codedHasCustomAttribute: {
+		tableMethodDef, tableField, tableTypeRef, tableTypeDef, tableParam, tableInterfaceImpl, tableMemberRef,
+		tableModule, tableDeclSecurity, tableProperty, tableEvent, tableStandAloneSig, tableModuleRef, tableTypeSpec,
+		tableAssembly, tableAssemblyRef, tableFile, tableExportedType, tableManifestResource, tableGenericParam,
+		tableGenericParamConstraint, tableMethodSpec,
+	},
+	codedHasFieldMarshal:     {tableField, tableParam},
+	codedHasDeclSecurity:     {tableTypeDef, tableMethodDef, tableAssembly},
+	codedMemberRefParent:     {tableTypeDef, tableTypeRef, tableModuleRef, tableMethodDef, tableTypeSpec},
+	codedHasSemantics:        {tableEvent, tableProperty},
+	codedMethodDefOrRef:      {tableMethodDef, tableMemberRef},
+	codedMemberForwarded:     {tableField, tableMethodDef},
+	codedImplementation:      {tableFile, tableAssemblyRef, tableExportedType},
+	codedCustomAttributeType: {unusedTable, unusedTable, tableMethodDef, tableMemberRef, unusedTable},
+	codedResolutionScope:     {tableModule, tableModuleRef, tableAssemblyRef, tableTypeRef},
+	codedTypeOrMethodDef:     {tableTypeDef, tableMethodDef},
+}
+
+// 欄位種類：固定長度、heap 索引、表索引（table + i）或 coded index（coded + i）
+const (
+	colU16 = iota
+	colU32
+	colString
+	colGUID
+	colBlob
+	colTable = 0x100
+	colCoded = 0x200
+)
+
+func tableColumn(table int) int { return colTable + table }
+func codedColumn(coded int) int { return colCoded + coded }
+
+// schema 為每個表的欄位（ECMA-335 II.22）
+var schema = [tableCount][]int{
+	tableModule:                 {colU16, colString, colGUID, colGUID, colGUID},
+	tableTypeRef:                {codedColumn(codedResolutionScope), colString, colString},
+	tableTypeDef:                {colU32, colString, colString, codedColumn(codedTypeDefOrRef), tableColumn(tableField), tableColumn(tableMethodDef)},
+	tableFieldPtr:               {tableColumn(tableField)},
+	tableField:                  {colU16, colString, colBlob},
+	tableMethodPtr:              {tableColumn(tableMethodDef)},
+	tableMethodDef:              {colU32, colU16, colU16, colString, colBlob, tableColumn(tableParam)},
+	tableParamPtr:               {tableColumn(tableParam)},
+	tableParam:                  {colU16, colU16, colString},
+	tableInterfaceImpl:          {tableColumn(tableTypeDef), codedColumn(codedTypeDefOrRef)},
+	tableMemberRef:              {codedColumn(codedMemberRefParent), colString, colBlob},
+	tableConstant:               {colU16, codedColumn(codedHasConstant), colBlob},
+	tableCustomAttribute:        {codedColumn(codedHasCustomAttribute), codedColumn(codedCustomAttributeType), colBlob},
+	tableFieldMarshal:           {codedColumn(codedHasFieldMarshal), colBlob},
+	tableDeclSecurity:           {colU16, codedColumn(codedHasDeclSecurity), colBlob},
+	tableClassLayout:            {colU16, colU32, tableColumn(tableTypeDef)},
+	tableFieldLayout:            {colU32, tableColumn(tableField)},
+	tableStandAloneSig:          {colBlob},
+	tableEventMap:               {tableColumn(tableTypeDef), tableColumn(tableEvent)},
+	tableEventPtr:               {tableColumn(tableEvent)},
+	tableEvent:                  {colU16, colString, codedColumn(codedTypeDefOrRef)},
+	tablePropertyMap:            {tableColumn(tableTypeDef), tableColumn(tableProperty)},
+	tablePropertyPtr:            {tableColumn(tableProperty)},
+	tableProperty:               {colU16, colString, colBlob},
+	tableMethodSemantics:        {colU16, tableColumn(tableMethodDef), codedColumn(codedHasSemantics)},
+	tableMethodImpl:             {tableColumn(tableTypeDef), codedColumn(codedMethodDefOrRef), codedColumn(codedMethodDefOrRef)},
+	tableModuleRef:              {colString},
+	tableTypeSpec:               {colBlob},
+	tableImplMap:                {colU16, codedColumn(codedMemberForwarded), colString, tableColumn(tableModuleRef)},
+	tableFieldRVA:               {colU32, tableColumn(tableField)},
+	tableEncLog:                 {colU32, colU32},
+	tableEncMap:                 {colU32},
+	tableAssembly:               {colU32, colU16, colU16, colU16, colU16, colU32, colBlob, colString, colString},
+	tableAssemblyProcessor:      {colU32},
+	tableAssemblyOS:             {colU32, colU32, colU32},
+	tableAssemblyRef:            {colU16, colU16, colU16, colU16, colU32, colBlob, colString, colString, colBlob},
+	tableAssemblyRefProcessor:   {colU32, tableColumn(tableAssemblyRef)},
+	tableAssemblyRefOS:          {colU32, colU32, colU32, tableColumn(tableAssemblyRef)},
+	tableFile:                   {colU32, colString, colBlob},
+	tableExportedType:           {colU32, colU32, colString, colString, codedColumn(codedImplementation)},
+	tableManifestResource:       {colU32, colU32, colString, codedColumn(codedImplementation)},
+	tableNestedClass:            {tableColumn(tableTypeDef), tableColumn(tableTypeDef)},
+	tableGenericParam:           {colU16, colU16, codedColumn(codedTypeOrMethodDef), colString},
+	tableMethodSpec:             {codedColumn(codedMethodDefOrRef), colBlob},
+	tableGenericParamConstraint: {tableColumn(tableGenericParam), codedColumn(codedTypeDefOrRef)},
+}
+
+// metadata 為解析後的 metadata root 與表
+type metadata struct {
+	version string // metadata root 的版本字串
+
+	strings, blobs []byte
+
+	stringSize, guidSize, blobSize uint32
+
+	rows    [tableCount]uint32
+	tables  [tableCount][]byte // 每個表的資料
+	rowSize [tableCount]uint32
+}
+
+// row 為表中的一列
+type row []byte
+
+func (r row) u16(offset uint32) uint16 { return binary.LittleEndian.Uint16(r[offset:]) }
+func (r row) u32(offset uint32) uint32 { return binary.LittleEndian.Uint32(r[offset:]) }
+
+// index 讀取位於 offset、長度為 size（2 或 4）的索引
+func (r row) index(offset, size uint32) uint32 {
+	if size == 2 {
+		return uint32(r.u16(offset))
+	}
+	return r.u32(offset)
+}
+
+// parseMetadata 解析 metadata root（ECMA-335 II.24.2.1）與 #~ 串流
+func parseMetadata(data []byte) (*metadata, error) {
+	if len(data) < 20 || binary.LittleEndian.Uint32(data) != 0x424a5342 {
+		return nil, fmt.Errorf("%w: missing metadata signature", ErrNotAssembly)
+	}
+	m := &metadata{}
+	length := binary.LittleEndian.Uint32(data[12:])
+	if uint64(16)+uint64(length)+4 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid assembly: truncated metadata root")
+	}
+	m.version = strings.TrimRight(string(data[16:16+length]), "\x00")
+	offset := 16 + length
+	count := binary.LittleEndian.Uint16(data[offset+2:])
+	offset += 4
+
+	var tables []byte
+	for i := uint16(0); i < count; i++ {
+		if uint64(offset)+8 > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid assembly: truncated stream headers")
+		}
+		start := binary.LittleEndian.Uint32(data[offset:])
+		size := binary.LittleEndian.Uint32(data[offset+4:])
+		end := bytes.IndexByte(data[offset+8:], 0)
+		if end < 0 {
+			return nil, fmt.Errorf("invalid assembly: truncated stream headers")
+		}
+		name := string(data[offset+8 : offset+8+uint32(end)])
+		offset += 8 + (uint32(end)+4)&^3
+		if uint64(start)+uint64(size) > uint64(len(data)) {
+			return nil, fmt.Errorf("invalid assembly: stream %s exceeds the metadata", name)
+		}
+		stream := data[start : start+size]
+		switch name {
+		case "#~", "#-":
+			tables = stream
+		case "#Strings":
+			m.strings = stream
+		case "#Blob":
+			m.blobs = stream
+		}
+	}
+	if tables == nil {
+		return nil, fmt.Errorf("invalid assembly: no metadata tables")
+	}
+	if err := m.parseTables(tables); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseTables 解析 #~ 串流（ECMA-335 II.24.2.6）：heap 大小、列數，並切出每個表的資料
+func (m *metadata) parseTables(data []byte) error {
+	if len(data) < 24 {
+		return fmt.Errorf("invalid assembly: truncated metadata tables")
+	}
+	heapSizes := data[6]
+	m.stringSize, m.guidSize, m.blobSize = 2, 2, 2
+	if heapSizes&0x01 != 0 {
+		m.stringSize = 4
+	}
+	if heapSizes&0x02 != 0 {
+		m.guidSize = 4
+	}
+	if heapSizes&0x04 != 0 {
+		m.blobSize = 4
+	}
+	valid := binary.LittleEndian.Uint64(data[8:])
+
+	offset := uint64(24)
+	for i := 0; i < tableCount; i++ {
+		if valid&(1<<uint(i)) == 0 {
+			continue
+		}
+		if offset+4 > uint64(len(data)) {
+			return fmt.Errorf("invalid assembly: truncated metadata tables")
+		}
+		m.rows[i] = binary.LittleEndian.Uint32(data[offset:])
+		offset += 4
+	}
+	// 未壓縮的 #- 串流在列數之後可能有額外的 4 個位元組
+	if heapSizes&0x40 != 0 {
+		offset += 4
+	}
+
+	for i := 0; i < tableCount; i++ {
+		if m.rows[i] == 0 {
+			continue
+		}
+		if schema[i] == nil {
+			return fmt.Errorf("invalid assembly: unknown metadata table 0x%02x", i)
+		}
+		for _, column := range schema[i] {
+			m.rowSize[i] += m.columnSize(column)
+		}
+		size := uint64(m.rows[i]) * uint64(m.rowSize[i])
+		if offset+size > uint64(len(data)) {
+			return fmt.Errorf("invalid assembly: metadata table 0x%02x is truncated", i)
+		}
+		m.tables[i] = data[offset : offset+size]
+		offset += size
+	}
+	return nil
+}
+
+// columnSize 回傳欄位的位元組數
+func (m *metadata) columnSize(column int) uint32 {
+	switch {
+	case column == colU16:
+		return 2
+	case column == colU32:
+		return 4
+	case column == colString:
+		return m.stringSize
+	case column == colGUID:
+		return m.guidSize
+	case column == colBlob:
+		return m.blobSize
+	case column >= colCoded:
+		return m.size(column - colCoded)
+	default:
+		return m.tableIndexSize(column - colTable)
+	}
+}
+
+// tableIndexSize 回傳指向 table 的索引位元組數
+func (m *metadata) tableIndexSize(table int) uint32 {
+	if m.rows[table] < 1<<16 {
+		return 2
+	}
+	return 4
+}
+
+// size 回傳 coded index 的位元組數：tag 使用的位元之外能容納所有表的列數時為 2
+func (m *metadata) size(coded int) uint32 {
+	tables := codedTables[coded]
+	bits := uint(0)
+	for 1<<bits < len(tables) {
+		bits++
+	}
+	for _, table := range tables {
+		if table != unusedTable && m.rows[table] >= 1<<(16-bits) {
+			return 4
+		}
+	}
+	return 2
+}
+
+// row 回傳 table 的第 index 列（從 1 開始）
+func (m *metadata) row(table int, index uint32) row {
+	size := m.rowSize[table]
+	return row(m.tables[table][(index-1)*size : index*size])
+}
+
+// string 回傳 #Strings heap 中 index 位置的字串
+func (m *metadata) string(index uint32) string {
+	if index >= uint32(len(m.strings)) {
+		return ""
+	}
+	data := m.strings[index:]
+	if end := bytes.IndexByte(data, 0); end >= 0 {
+		data = data[:end]
+	}
+	return string(data)
+}
+
+// blob 回傳 #Blob heap 中 index 位置的內容
+func (m *metadata) blob(index uint32) []byte {
+	if index == 0 || index >= uint32(len(m.blobs)) {
+		return nil
+	}
+	length, n, ok := compressedUint(m.blobs[index:])
+	if !ok || uint64(index)+uint64(n)+uint64(length) > uint64(len(m.blobs)) {
+		return nil
+	}
+	start := index + uint32(n)
+	return m.blobs[start : start+length]
+}
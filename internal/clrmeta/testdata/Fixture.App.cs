@@ -0,0 +1,12 @@
+using System.Reflection;
+
+[assembly: AssemblyVersion("3.0.0.0")]
+[assembly: AssemblyCulture("de")]
+
+namespace Fixture.App
+{
+    public class Program
+    {
+        public static int Run() { return new Fixture.Lib.Greeter().Greet(); }
+    }
+}
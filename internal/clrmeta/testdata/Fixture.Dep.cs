@@ -0,0 +1,11 @@
+using System.Reflection;
+
+[assembly: AssemblyVersion("2.0.0.0")]
+
+namespace Fixture.Dep
+{
+    public class Helper
+    {
+        public static int Value() { return 42; }
+    }
+}
@@ -0,0 +1,17 @@
+using System.Reflection;
+using System.Runtime.Versioning;
+
+#if V2
+[assembly: AssemblyVersion("2.0.0.0")]
+#else
+[assembly: AssemblyVersion("1.2.3.4")]
+#endif
+[assembly: TargetFramework(".NETStandard,Version=v2.0", FrameworkDisplayName = ".NET Standard 2.0")]
+
+namespace Fixture.Lib
+{
+    public class Greeter
+    {
+        public int Greet() { return Fixture.Dep.Helper.Value(); }
+    }
+}
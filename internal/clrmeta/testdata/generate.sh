@@ -0,0 +1,23 @@
+#!/bin/sh
+# 以 .NET SDK 附帶的 C# 編譯器重新產生測試組件：
+#   Fixture.Dep.dll      未簽署的依賴
+#   Fixture.Dep.x64.dll  同一個依賴的 PE32+（x64）版本
+#   Fixture.Lib.dll      以 fixture.pub public sign，參照 System.Runtime 與 Fixture.Dep
+#   Fixture.Lib.Ref.dll  Fixture.Lib 的參考組件（ReferenceAssemblyAttribute）
+#   v2/Fixture.Lib.dll   版本為 2.0.0.0 的 Fixture.Lib（定義 V2），以相同金鑰 public sign
+#   Fixture.App.dll      culture 為 de、參照 Fixture.Lib（由編譯器計算其 public key token）
+# 用法：DOTNET_ROOT=/path/to/dotnet ./generate.sh
+set -e
+cd "$(dirname "$0")"
+DOTNET_ROOT=${DOTNET_ROOT:-$HOME/.dotnet}
+CSC=$(ls -d "$DOTNET_ROOT"/sdk/*/Roslyn/bincore/csc.dll | tail -n 1)
+REF=$(ls -d "$DOTNET_ROOT"/packs/Microsoft.NETCore.App.Ref/*/ref/net*.0 | tail -n 1)
+csc() {
+	"$DOTNET_ROOT/dotnet" "$CSC" -nologo -noconfig -nostdlib -target:library -deterministic -optimize+ -nowarn:CS8002 \
+		-r:"$REF/System.Runtime.dll" "$@"
+}
+csc -out:Fixture.Dep.dll Fixture.Dep.cs
+csc -out:Fixture.Dep.x64.dll -platform:x64 Fixture.Dep.cs
+csc -out:Fixture.Lib.dll -refout:Fixture.Lib.Ref.dll -publicsign -keyfile:fixture.pub -r:Fixture.Dep.dll Fixture.Lib.cs
+csc -out:v2/Fixture.Lib.dll -define:V2 -publicsign -keyfile:fixture.pub -r:Fixture.Dep.dll Fixture.Lib.cs
+csc -out:Fixture.App.dll -r:Fixture.Lib.dll -r:Fixture.Dep.dll Fixture.App.cs
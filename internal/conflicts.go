@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/utils"
+)
+
+// AssemblyConflict 為匯出集合中由多個套件帶入的同一組件（名稱與文化特性相同），只保留最高版本
+type AssemblyConflict struct {
+	Name    string
+	Kept    AssemblySource
+	Dropped []AssemblySource
+	// StrongNameReferences 為以強式名稱參照較低版本的組件；Unity 沒有 binding redirect，這些參照可能無法載入
+	StrongNameReferences []string
+}
+
+// AssemblySource 為組件的一個來源
+type AssemblySource struct {
+	Package string // 套件 ID 與版本
+	Version clrmeta.Version
+	Path    string // 匯出的套件內以 "/" 分隔的路徑
+}
+
+func (s AssemblySource) String() string {
+	return fmt.Sprintf("%s from %s", s.Version, s.Package)
+}
+
+// assemblyCandidate 為複製到某個匯出套件 Runtime/ 下的一個 lib/ 組件
+type assemblyCandidate struct {
+	AssemblySource
+	staged   *stagedPackage
+	source   string // 套件中的原始檔案
+	assembly *clrmeta.Assembly
+}
+
+// resolveConflicts 找出 staged 中由多個套件帶入的同一組件：保留最高版本（必要時還原被較低版本覆寫的檔案），
+// 移除其他複本，並回報以強式名稱要求較低版本的參照。Runtime/<平台> 下的 RID 專用組件不列入
+func (run *exportRun) resolveConflicts(staged []*stagedPackage) error {
+	candidates, err := collectCandidates(staged)
+	if err != nil {
+		return err
+	}
+	groups := map[string][]*assemblyCandidate{}
+	var order []string
+	for _, c := range candidates {
+		key := strings.ToLower(c.assembly.Name) + "," + strings.ToLower(c.assembly.Culture)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	changed := map[*stagedPackage]bool{}
+	for _, key := range order {
+		group := groups[key]
+		kept := group[0]
+		conflict := false
+		for _, c := range group[1:] {
+			if c.Version != kept.Version || c.staged != kept.staged || c.Path != kept.Path {
+				conflict = true
+			}
+			if c.Version.Compare(kept.Version) > 0 {
+				kept = c
+			}
+		}
+		if !conflict {
+			continue
+		}
+
+		result := AssemblyConflict{Name: kept.assembly.Name, Kept: kept.AssemblySource}
+		involved := []*stagedPackage{kept.staged}
+		for _, c := range group {
+			if c == kept {
+				continue
+			}
+			result.Dropped = append(result.Dropped, c.AssemblySource)
+			involved = append(involved, c.staged)
+			if c.staged == kept.staged && c.Path == kept.Path {
+				continue
+			}
+			// 同一個檔案可能由多個來源複製，只移除一次
+			if err := os.Remove(filepath.Join(c.staged.dir, filepath.FromSlash(c.Path))); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove duplicate assembly %s: %v", c.Path, err)
+			}
+			delete(c.staged.plugins, c.Path)
+			changed[c.staged] = true
+			if strings.EqualFold(path.Base(c.Path), c.staged.asmdefDLL) {
+				// asmdef 以這個組件命名，建立的話會與保留它的套件的 asmdef 同名
+				c.staged.asmdefDLL = ""
+			}
+		}
+		// 後複製的較低版本可能覆寫了保留的檔案
+		if err := utils.CopyFile(kept.source, filepath.Join(kept.staged.dir, filepath.FromSlash(kept.Path))); err != nil {
+			return fmt.Errorf("failed to restore %s: %v", kept.Path, err)
+		}
+		changed[kept.staged] = true
+
+		dropped := make([]string, len(result.Dropped))
+		for i, d := range result.Dropped {
+			dropped[i] = d.String()
+		}
+		message := fmt.Sprintf("%s is exported by several packages; keeping %s, dropping %s", result.Name, result.Kept, strings.Join(dropped, ", "))
+		progress.Warnf(run.report, progress.StageValidate, "%s", message)
+		seen := map[*stagedPackage]bool{}
+		for _, s := range involved {
+			if !seen[s] {
+				seen[s] = true
+				s.result.Conflicts = append(s.result.Conflicts, result)
+				s.result.Warnings = append(s.result.Warnings, message)
+			}
+		}
+	}
+
+	// 重新讀取有變動的套件，讓參照檢查只看到保留的組件
+	for _, s := range staged {
+		if !changed[s] {
+			continue
+		}
+		assemblies, _, err := inspectAssemblies(s.dir, run.profile, run.targets, progress.Discard)
+		if err != nil {
+			return err
+		}
+		s.result.Assemblies = assemblies
+	}
+	run.reportStrongNameReferences(staged)
+	return nil
+}
+
+// collectCandidates 列出每個套件 lib/<框架> 下、已複製到匯出套件 Runtime/ 的 .NET 組件
+func collectCandidates(staged []*stagedPackage) ([]*assemblyCandidate, error) {
+	var candidates []*assemblyCandidate
+	for _, s := range staged {
+		for i, pkg := range s.packages {
+			framework := s.result.Packages[i].Framework
+			if framework == "" {
+				continue
+			}
+			files, err := filepath.Glob(filepath.Join(pkg.InstallDir, "lib", framework, "*.dll"))
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				rel := path.Join("Runtime", filepath.Base(file))
+				if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(rel))); err != nil {
+					continue // 被 filter 排除
+				}
+				assembly, err := clrmeta.ReadFile(file)
+				if errors.Is(err, clrmeta.ErrNotAssembly) {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				candidates = append(candidates, &assemblyCandidate{
+					AssemblySource: AssemblySource{Package: pkg.ID + " " + pkg.Version.String(), Version: assembly.Version, Path: rel},
+					staged:         s,
+					source:         file,
+					assembly:       assembly,
+				})
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// reportStrongNameReferences 回報以強式名稱參照衝突組件較低版本的組件
+func (run *exportRun) reportStrongNameReferences(staged []*stagedPackage) {
+	for _, s := range staged {
+		for i := range s.result.Conflicts {
+			conflict := &s.result.Conflicts[i]
+			for _, other := range staged {
+				for _, assembly := range other.result.Assemblies {
+					for _, ref := range assembly.References {
+						if !strings.EqualFold(ref.Name, conflict.Name) || ref.PublicKeyToken == "" || ref.Version.Compare(conflict.Kept.Version) >= 0 {
+							continue
+						}
+						message := fmt.Sprintf("%s requires %s %s by strong name, but %s is exported; Unity has no binding redirects",
+							path.Base(assembly.Path), ref.Name, ref.Version, conflict.Kept.Version)
+						conflict.StrongNameReferences = append(conflict.StrongNameReferences, message)
+						if other == s {
+							progress.Warnf(run.report, progress.StageValidate, "%s", message)
+							s.result.Warnings = append(s.result.Warnings, message)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
+)
+
+// fixtureFile 讀取 clrmeta 的測試組件
+func fixtureFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("clrmeta", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// conflictPackage 為 stageConflict 的一個套件：lib/netstandard2.0 下的檔案名稱與內容
+type conflictPackage struct {
+	id, version string
+	files       map[string][]byte
+}
+
+// stageConflict 建立依序複製 packages 的 lib/netstandard2.0 組件的 stagedPackage，後複製的同名檔案覆寫先前的
+func stageConflict(t *testing.T, name, asmdefDLL string, packages ...conflictPackage) *stagedPackage {
+	t.Helper()
+	s := &stagedPackage{
+		name:      name,
+		dir:       t.TempDir(),
+		result:    &ExportResult{PackageID: name},
+		plugins:   map[string]unitypackage.PluginSettings{},
+		asmdefDLL: asmdefDLL,
+	}
+	for _, p := range packages {
+		installDir := t.TempDir()
+		for file, data := range p.files {
+			writeTestFile(t, filepath.Join(installDir, "lib", "netstandard2.0", file), data)
+		}
+		for file, data := range p.files {
+			writeTestFile(t, filepath.Join(s.dir, "Runtime", file), data)
+			s.plugins["Runtime/"+file] = unitypackage.DefaultPluginSettings()
+		}
+		s.packages = append(s.packages, &nuget.ResolvedPackage{ID: p.id, Version: nuget.MustParseVersion(p.version), InstallDir: installDir})
+		s.result.Packages = append(s.result.Packages, PackageResult{ID: p.id, Version: p.version, Framework: "netstandard2.0"})
+	}
+	return s
+}
+
+func TestResolveConflicts(t *testing.T) {
+	libV1 := fixtureFile(t, "Fixture.Lib.dll")
+	libV2 := fixtureFile(t, filepath.Join("v2", "Fixture.Lib.dll"))
+	app := fixtureFile(t, "Fixture.App.dll")
+
+	// Foo 帶入 Fixture.Lib 2.0.0.0，其依賴的 Fixture.Lib 套件之後複製、以 1.2.3.4 覆寫了同一個檔案；
+	// Fixture.App 以強式名稱參照 1.2.3.4。Bar 為另一個匯出的 Fixture.Lib 1.2.3 套件
+	foo := stageConflict(t, "Foo", "Foo.dll",
+		conflictPackage{"Foo", "1.0.0", map[string][]byte{"Fixture.Lib.dll": libV2}},
+		conflictPackage{"Fixture.Lib", "1.2.3", map[string][]byte{"Fixture.Lib.dll": libV1}},
+		conflictPackage{"Fixture.App", "3.0.0", map[string][]byte{"Fixture.App.dll": app}},
+	)
+	bar := stageConflict(t, "Bar", "Fixture.Lib.dll",
+		conflictPackage{"Fixture.Lib", "1.2.3", map[string][]byte{"Fixture.Lib.dll": libV1}},
+	)
+	run := &exportRun{profile: unity.DefaultProfile, targets: nuget.ProfileTargets(unity.DefaultProfile), report: progress.Discard}
+	if err := run.resolveConflicts([]*stagedPackage{foo, bar}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 保留最高版本，並還原被覆寫的檔案
+	kept, err := os.ReadFile(filepath.Join(foo.dir, "Runtime", "Fixture.Lib.dll"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(kept, libV2) {
+		t.Error("Foo/Runtime/Fixture.Lib.dll was not restored to version 2.0.0.0")
+	}
+	if _, ok := foo.plugins["Runtime/Fixture.Lib.dll"]; !ok {
+		t.Error("the kept assembly lost its plugin settings")
+	}
+	if foo.asmdefDLL != "Foo.dll" {
+		t.Errorf("Foo asmdefDLL = %q, want Foo.dll", foo.asmdefDLL)
+	}
+
+	// 其他套件的複本被移除，asmdef 不再以它命名
+	if _, err := os.Stat(filepath.Join(bar.dir, "Runtime", "Fixture.Lib.dll")); !os.IsNotExist(err) {
+		t.Errorf("Bar/Runtime/Fixture.Lib.dll was not removed: %v", err)
+	}
+	if _, ok := bar.plugins["Runtime/Fixture.Lib.dll"]; ok {
+		t.Error("the dropped assembly kept its plugin settings")
+	}
+	if bar.asmdefDLL != "" {
+		t.Errorf("Bar asmdefDLL = %q, want it cleared", bar.asmdefDLL)
+	}
+	if len(bar.result.Assemblies) != 0 {
+		t.Errorf("Bar assemblies = %+v, want none after the conflict", bar.result.Assemblies)
+	}
+
+	strongName := "Fixture.App.dll requires Fixture.Lib 1.2.3.4 by strong name, but 2.0.0.0 is exported; Unity has no binding redirects"
+	libPath := "Runtime/Fixture.Lib.dll"
+	want := AssemblyConflict{
+		Name: "Fixture.Lib",
+		Kept: AssemblySource{Package: "Foo 1.0.0", Version: clrmeta.Version{Major: 2}, Path: libPath},
+		Dropped: []AssemblySource{
+			{Package: "Fixture.Lib 1.2.3", Version: clrmeta.Version{Major: 1, Minor: 2, Build: 3, Revision: 4}, Path: libPath},
+			{Package: "Fixture.Lib 1.2.3", Version: clrmeta.Version{Major: 1, Minor: 2, Build: 3, Revision: 4}, Path: libPath},
+		},
+		StrongNameReferences: []string{strongName},
+	}
+	for _, s := range []*stagedPackage{foo, bar} {
+		if !reflect.DeepEqual(s.result.Conflicts, []AssemblyConflict{want}) {
+			t.Errorf("%s conflicts = %+v, want %+v", s.name, s.result.Conflicts, want)
+		}
+	}
+	message := "Fixture.Lib is exported by several packages; keeping 2.0.0.0 from Foo 1.0.0, dropping 1.2.3.4 from Fixture.Lib 1.2.3, 1.2.3.4 from Fixture.Lib 1.2.3"
+	// 強式名稱的警告只加在參照者所在的套件
+	if want := []string{message, strongName}; !reflect.DeepEqual(foo.result.Warnings, want) {
+		t.Errorf("Foo warnings = %q, want %q", foo.result.Warnings, want)
+	}
+	if want := []string{message}; !reflect.DeepEqual(bar.result.Warnings, want) {
+		t.Errorf("Bar warnings = %q, want %q", bar.result.Warnings, want)
+	}
+}
+
+func TestResolveConflictsSameVersion(t *testing.T) {
+	// 同一個套件只複製一次的組件不是衝突
+	lib := fixtureFile(t, "Fixture.Lib.dll")
+	foo := stageConflict(t, "Foo", "Fixture.Lib.dll", conflictPackage{"Fixture.Lib", "1.2.3", map[string][]byte{"Fixture.Lib.dll": lib}})
+	run := &exportRun{profile: unity.DefaultProfile, targets: nuget.ProfileTargets(unity.DefaultProfile), report: progress.Discard}
+	if err := run.resolveConflicts([]*stagedPackage{foo}); err != nil {
+		t.Fatal(err)
+	}
+	if len(foo.result.Conflicts) != 0 || len(foo.result.Warnings) != 0 || foo.asmdefDLL != "Fixture.Lib.dll" {
+		t.Errorf("conflicts = %+v, warnings = %q, asmdefDLL = %q", foo.result.Conflicts, foo.result.Warnings, foo.asmdefDLL)
+	}
+}
@@ -1,93 +1,858 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
 	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
 	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
 )
 
+// ExportOptions 描述一次匯出的參數
+type ExportOptions struct {
+	PackageID       string
+	VersionRange    string // 精確版本、範圍 ("[1.2,2.0)") 或浮動版本 ("13.0.*")，空字串或 "latest" 代表最新版
+	AllowPrerelease bool
+	ExportPath      string
+
+	// Profile 為目標 Unity 版本與 API level，決定可用的目標框架與哪些組件由 Unity 內建而不匯出；
+	// 零值使用 unity.DefaultProfile
+	Profile unity.Profile
+	// AllowAssemblies 即使 Unity 已內建仍要匯出的組件或套件
+	AllowAssemblies []string
+	// DenyAssemblies 一律不匯出的組件或套件
+	DenyAssemblies []string
+	// PreviousGUIDs 為先前匯出的 .unitypackage 或 Unity 專案路徑，其中已存在的資產沿用原本的 GUID
+	PreviousGUIDs string
+	// PluginSettings 為匯出 DLL 的 PluginImporter 設定（preload、參照檢查、define constraints 與平台），
+	// nil 使用 unitypackage.DefaultPluginSettings
+	PluginSettings *unitypackage.PluginSettings
+	// Asmdef 為匯出的 asmdef 的平台、define constraints、version defines 等設定，nil 使用預設值
+	Asmdef *packagemanifest.AsmdefSettings
+	// SkipAnalyzers 不匯出套件 analyzers/ 下的 Roslyn analyzer 與 source generator
+	SkipAnalyzers bool
+	// AnalyzerLanguage 為要匯出的 analyzer 語言（"cs" 或 "vb"），空字串為 "cs"
+	AnalyzerLanguage string
+	// Progress 接收匯出過程的事件，nil 時將訊息印在標準輸出
+	Progress progress.Reporter `json:"-"`
+
+	// Framework 指定 root 套件使用的 lib/ 資料夾或目標框架（如 "netstandard2.0"），空字串依 Profile 自動選擇
+	Framework string
+
+	// Format 為輸出格式，空字串為 FormatUnityPackage
+	Format string
+	// OutputDir 為輸出檔案的目錄，空字串為目前目錄；FormatFolder 在其下建立 <套件>/ 資料夾
+	OutputDir string
+	// AssetRoot 為 .unitypackage 匯入後套件資料夾的上層（如 "Assets/Plugins/NuGet"），空字串為 "Assets"
+	AssetRoot string
+	// StrictReferences 為 true 時，匯出的組件參照了沒有匯出、Unity 也未內建的組件則匯出失敗
+	// （ErrMissingReferences），否則只加入警告
+	StrictReferences bool
+	// AllowReferences 為執行時另外提供、不需檢查的組件參照，以 "*" 結尾者為前綴比對（如 "Unity.*"）
+	AllowReferences []string
+	// LinkXML 為 true 時在 Runtime/ 產生 link.xml，讓 IL2CPP 保留已知以反射建立型別的匯出組件（unity.KnownPreserves）
+	LinkXML bool
+	// Preserve 為寫入 link.xml 的匯出組件，"組件/命名空間" 只保留該命名空間，"*" 為所有匯出的組件；
+	// 不為空時即使 LinkXML 為 false 也會產生 link.xml
+	Preserve []string
+
+	// UPMDependencies 為 true 時不匯出依賴的組件，改將直接依賴以 com.nuget.* 寫入 package.json 的 dependencies，
+	// 由 Unity Package Manager 從同一個 registry 安裝（只支援 FormatTarball 與 FormatFolder，不支援 ExportBatch）
+	UPMDependencies bool
+
+	// Bundle 不為空時，ExportBatch 將所有套件打包成一個 <OutputDir>/<Bundle>.unitypackage（只支援 FormatUnityPackage）
+	Bundle string
+}
+
+// 輸出格式
+const (
+	FormatUnityPackage = "unitypackage" // 解壓至 Assets/<套件> 的 .unitypackage
+	FormatTarball      = "tgz"          // 以 file: 或 registry 安裝的 UPM 套件
+	FormatFolder       = "folder"       // 附 .meta 的套件資料夾，可直接放進 Packages/ 或 Assets/
+)
+
+// ExportResult 為匯出的結果，回報給呼叫端
+type ExportResult struct {
+	PackageID string
+	Version   string
+	Profile   unity.Profile
+	Framework nuget.FrameworkSelection // root 套件選中的框架與原因
+	Packages  []PackageResult          // root 與所有依賴
+	Warnings  []string
+	Files     []string // 套件內的檔案（不含 .meta），以 "/" 分隔的相對路徑
+
+	Assemblies        []AssemblyResult   // Runtime/ 下 .NET 組件的 metadata
+	Conflicts         []AssemblyConflict // 多個套件帶入同一組件時的處理結果
+	MissingReferences []MissingReference // 無法滿足的組件參照
+	Asmdef            string             // 產生的 asmdef 在套件內的路徑，沒有產生時為空字串
+	LinkXML           string             // 產生的 link.xml 在套件內的路徑，沒有產生時為空字串
+	Preserved         []unity.Preserve   // link.xml 保留的組件與命名空間
+
+	ArtifactPath string // 產生的 .unitypackage 或 .tgz 路徑
+}
+
+// PackageResult 為依賴圖中單一套件的匯出結果
+type PackageResult struct {
+	ID        string
+	Version   string
+	Framework string // 空字串代表沒有可匯出的組件
+	Copied    int
+	Native    int // 複製的 native plugin 檔案數
+	Analyzers int // 複製的 Roslyn analyzer 數
+}
+
 // ExportNugetPackageToUnity 是高階函式，整合所有功能：
 // 1. 使用 nuget 下載指定套件
 // 2. 選擇框架並複製 DLL
 // 3. 建立 package.json 與 asmdef
 // 4. 將結果打包成 unitypackage
 func ExportNugetPackageToUnity(nugetPackageName, packageVersion, exportPath string) error {
+	_, err := Export(ExportOptions{
+		PackageID:    nugetPackageName,
+		VersionRange: packageVersion,
+		ExportPath:   exportPath,
+	})
+	return err
+}
+
+// ErrDeniedPackage 表示要求匯出的套件本身在 deny 清單（或批次清單的 exclude）中
+var ErrDeniedPackage = errors.New("the requested package is denied")
+
+// checkRootDenied 在 filter 的 deny 清單包含 root 套件 id 時回傳 ErrDeniedPackage
+func checkRootDenied(id string, filter *unity.AssemblyFilter) error {
+	if filter.Denies(id) {
+		return fmt.Errorf("%w: remove %s from the deny list to export it", ErrDeniedPackage, id)
+	}
+	return nil
+}
+
+// Export 依 ExportOptions 執行完整的匯出流程
+func Export(opts ExportOptions) (*ExportResult, error) {
+	run, err := newExportRun(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer run.close()
+
+	if err := checkRootDenied(opts.PackageID, run.filter); err != nil {
+		return nil, err
+	}
+	root, selection, err := run.installRoot(opts.PackageID, opts.VersionRange, opts.AllowPrerelease, opts.Framework)
+	if err != nil {
+		return nil, err
+	}
+
+	// 依相容的目標框架解析遞移依賴
+	run.resolver.TargetFramework = selection.Target.ShortFolderName()
+	if opts.UPMDependencies {
+		// 與 UPMDependencies 選擇相同的依賴群組，package.json 才會與 registry 的 packument 一致
+		root.TargetFramework = root.Nuspec.DependencyFramework(run.targets).ShortFolderName()
+	}
+	progress.Infof(run.report, progress.StageResolve, "Resolving dependencies for %s", run.resolver.TargetFramework)
+	graph, err := run.resolver.ResolveDependencies(root)
+	if err != nil {
+		return nil, fmt.Errorf("dependency resolution failed: %w", err)
+	}
+	run.reportGraph(graph)
+
+	deps := graph.Packages[1:]
+	var upmDependencies map[string]string
+	if opts.UPMDependencies {
+		if upmDependencies, err = UPMDependencies(run.resolver, root.Nuspec, run.targets); err != nil {
+			return nil, err
+		}
+		// 依賴由各自的 UPM 套件提供，參照其組件（通常與套件 id 同名）不算缺少
+		allow := append([]string{}, run.opts.AllowReferences...)
+		for _, dep := range deps {
+			allow = append(allow, dep.ID)
+		}
+		run.opts.AllowReferences = allow
+		deps = nil
+	}
+	staged, err := run.stagePackage(opts.PackageID, root, selection, deps, run.filter, upmDependencies)
+	if err != nil {
+		return nil, err
+	}
+	if err := run.resolveConflicts([]*stagedPackage{staged}); err != nil {
+		return nil, err
+	}
+	if err := run.checkReferences([]*stagedPackage{staged}); err != nil {
+		return nil, err
+	}
+	warnings, err := run.writeGeneratedFiles([]*stagedPackage{staged})
+	if err != nil {
+		return nil, err
+	}
+	if err := run.pack(staged); err != nil {
+		return nil, err
+	}
+	result := staged.result
+	result.Warnings = append(append(graph.Warnings, result.Warnings...), warnings...)
+	return result, nil
+}
+
+// exportRun 為一次匯出共用的設定、下載目錄與 resolver，批次匯出的所有套件共用同一個 exportRun
+type exportRun struct {
+	opts             ExportOptions
+	profile          unity.Profile
+	targets          []nuget.Framework
+	format           string
+	analyzerLanguage string
+	report           progress.Reporter
+	outputDir        string
+	assetRoot        string
+	previousGUIDs    unitypackage.GUIDMap
+	preserves        []unity.Preserve
+	filter           *unity.AssemblyFilter
+	client           *nuget.Client
+	resolver         *nuget.Resolver
+	tempDir          string
+}
+
+// newExportRun 檢查 opts 並建立下載用的暫存目錄，使用完畢須呼叫 close
+func newExportRun(opts ExportOptions) (*exportRun, error) {
+	run := &exportRun{opts: opts, profile: opts.Profile, report: opts.Progress, outputDir: opts.OutputDir}
+	if run.profile.IsZero() {
+		run.profile = unity.DefaultProfile
+	}
+	if err := run.profile.Validate(); err != nil {
+		return nil, err
+	}
+	run.targets = nuget.ProfileTargets(run.profile)
+
+	run.format = strings.ToLower(opts.Format)
+	if run.format == "" {
+		run.format = FormatUnityPackage
+	}
+	if run.format != FormatUnityPackage && run.format != FormatTarball && run.format != FormatFolder {
+		return nil, fmt.Errorf("invalid output format %q: use %s, %s or %s", opts.Format, FormatUnityPackage, FormatTarball, FormatFolder)
+	}
+	if opts.UPMDependencies && run.format == FormatUnityPackage {
+		return nil, fmt.Errorf("UPM dependencies require the %s or %s format", FormatTarball, FormatFolder)
+	}
+
+	run.analyzerLanguage = strings.ToLower(opts.AnalyzerLanguage)
+	if run.analyzerLanguage == "" {
+		run.analyzerLanguage = "cs"
+	}
+	if run.analyzerLanguage != "cs" && run.analyzerLanguage != "vb" {
+		return nil, fmt.Errorf("invalid analyzer language %q: use cs or vb", opts.AnalyzerLanguage)
+	}
+
+	assetRoot, err := unitypackage.NormalizeAssetRoot(opts.AssetRoot)
+	if err != nil {
+		return nil, err
+	}
+	run.assetRoot = assetRoot
+	if run.preserves, err = parsePreserves(opts.Preserve); err != nil {
+		return nil, err
+	}
+
+	if run.report == nil {
+		run.report = progress.NewConsole(os.Stdout)
+	}
+	if run.outputDir == "" {
+		run.outputDir = "."
+	}
+	if opts.PreviousGUIDs != "" {
+		previousGUIDs, err := unitypackage.LoadGUIDMap(opts.PreviousGUIDs)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading previous GUIDs from %s: %v", opts.PreviousGUIDs, err)
+		}
+		run.previousGUIDs = previousGUIDs
+		progress.Infof(run.report, progress.StagePack, "Loaded %d existing GUID(s) from %s", len(previousGUIDs), opts.PreviousGUIDs)
+	}
+
 	// 建立暫存目錄
 	tempDir, err := os.MkdirTemp("", "nuget_temp")
 	if err != nil {
-		return fmt.Errorf("failed to create temporary directory: %v", err)
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
 	}
-	defer os.RemoveAll(tempDir)
+	run.tempDir = tempDir
+	run.filter = unity.NewAssemblyFilter(run.profile, opts.AllowAssemblies, opts.DenyAssemblies)
+	run.client = nuget.DefaultClient()
+	run.resolver = nuget.NewResolver(run.client, tempDir)
+	run.resolver.AllowPrerelease = opts.AllowPrerelease
+	run.resolver.Filter = run.filter
+	run.resolver.Progress = run.report
+	return run, nil
+}
 
-	pluginPath := filepath.Join(exportPath, nugetPackageName)
+func (run *exportRun) close() {
+	os.RemoveAll(run.tempDir)
+}
 
-	// 下載套件
-	fmt.Printf("\nDownloading %s (%s) to temporary directory...\n", nugetPackageName, packageVersion)
-	err = nuget.DownloadPackage(nugetPackageName, packageVersion, tempDir)
+// installRoot 依版本範圍從 feed 選出版本、下載套件並選擇其框架；framework 不為空時使用指定的框架
+func (run *exportRun) installRoot(packageID, requested string, prerelease bool, framework string) (*nuget.ResolvedPackage, nuget.FrameworkSelection, error) {
+	var selection nuget.FrameworkSelection
+	versionRange, err := nuget.ParseVersionRange(requested)
 	if err != nil {
-		return fmt.Errorf("NuGet install package failed: %v", err)
+		return nil, selection, fmt.Errorf("invalid package version %q: %v", requested, err)
 	}
-
-	// 找到安裝目錄
-	packageInstallDir, err := nuget.FindInstalledPackageDir(nugetPackageName, tempDir)
+	progress.Emit(run.report, progress.Event{Stage: progress.StageResolve, Package: packageID, Message: fmt.Sprintf("Resolving %s %s", packageID, versionRange)})
+	version, err := run.client.ResolveVersion(packageID, versionRange, prerelease)
 	if err != nil {
-		return err
+		return nil, selection, err
 	}
+	packageVersion := version.String()
 
-	// 從目錄推斷版本
-	packageVersion, err = nuget.DetermineVersionIfEmpty(nugetPackageName, packageVersion, packageInstallDir)
+	// 下載套件
+	progress.Emit(run.report, progress.Event{Stage: progress.StageResolve, Package: packageID, Version: packageVersion, Message: fmt.Sprintf("Selected %s %s (requested %s)", packageID, packageVersion, versionRange)})
+	root, err := run.resolver.Install(packageID, version)
 	if err != nil {
-		return err
+		return nil, selection, fmt.Errorf("NuGet install package failed: %w", err)
 	}
 
 	// 找框架
-	frameworkDirs, err := nuget.ListFrameworks(packageInstallDir)
+	frameworkDirs, err := nuget.ListFrameworks(root.InstallDir)
+	if err != nil {
+		return nil, selection, err
+	}
+	rootRuntimes, err := nuget.ListRuntimeAssets(root.InstallDir)
 	if err != nil {
-		return err
+		return nil, selection, err
+	}
+	if len(frameworkDirs) == 0 && len(rootRuntimes) == 0 {
+		return nil, selection, fmt.Errorf("No target frameworks found under 'lib' for package %s: %w", packageID, nuget.ErrNoCompatibleFramework)
+	}
+
+	// 只有 native 檔案的套件（如 SQLitePCLRaw.lib.e_sqlite3）沒有 lib/，依第一個目標框架解析依賴
+	selection = nuget.FrameworkSelection{Target: run.targets[0], Reason: "package has no managed assemblies"}
+	if framework != "" {
+		selection, err = nuget.FindFramework(frameworkDirs, framework)
+		if err != nil {
+			return nil, selection, fmt.Errorf("%s %s: %w", packageID, packageVersion, err)
+		}
+		progress.Infof(run.report, progress.StageFramework, "Using target framework: %s (%s)", selection.Folder, selection.Reason)
+	} else if len(frameworkDirs) > 0 {
+		selection, err = nuget.SelectFramework(frameworkDirs, run.targets)
+		if err != nil {
+			return nil, selection, fmt.Errorf("%s %s has no framework usable by %s: %w", packageID, packageVersion, run.profile, err)
+		}
+		progress.Infof(run.report, progress.StageFramework, "Using target framework: %s (%s)", selection.Folder, selection.Reason)
+	}
+	return root, selection, nil
+}
+
+// reportGraph 回報依賴圖中被排除的套件與警告
+func (run *exportRun) reportGraph(graph *nuget.DependencyGraph) {
+	for _, id := range graph.Excluded {
+		progress.Infof(run.report, progress.StageResolve, "Excluded dependency %s: %s", id, run.filter.Reason(id))
+	}
+	for _, warning := range graph.Warnings {
+		progress.Warnf(run.report, progress.StageResolve, "%s", warning)
 	}
+}
+
+// stagedPackage 為已複製好、尚未打包的套件
+type stagedPackage struct {
+	name      string
+	dir       string
+	result    *ExportResult
+	packages  []*nuget.ResolvedPackage // root 與依賴，順序同 result.Packages
+	plugins   map[string]unitypackage.PluginSettings
+	asmName   string           // root 的主要組件，asmdef 以它命名
+	asmdefDLL string           // root 的主要 DLL，空字串時不建立 asmdef
+	preserves []unity.Preserve // 批次清單中此套件的 preserve
+}
+
+// stagePackage 將 root 與 deps 的組件複製到 <ExportPath>/<name>（資料夾格式為 <OutputDir>/<name>），
+// 並建立列出 upmDependencies 的 package.json；asmdef 與 link.xml 在組件衝突處理後由 writeGeneratedFiles 建立
+func (run *exportRun) stagePackage(name string, root *nuget.ResolvedPackage, selection nuget.FrameworkSelection, deps []*nuget.ResolvedPackage, filter *unity.AssemblyFilter, upmDependencies map[string]string) (*stagedPackage, error) {
+	report := run.report
+	packageVersion := root.Version.String()
+	selectedFramework := selection.Folder
+
+	pluginPath := filepath.Join(run.opts.ExportPath, name)
+	if run.format == FormatFolder {
+		// 資料夾直接輸出到 outputDir
+		pluginPath = filepath.Join(run.outputDir, name)
+	}
+	// 覆寫前一次的輸出，以免舊版本的檔案被一起打包
+	if err := removePreviousExport(pluginPath); err != nil {
+		return nil, err
+	}
+	runtimePath := filepath.Join(pluginPath, "Runtime")
 
-	selectedFramework := nuget.ChooseFrameworkAuto(frameworkDirs)
-	fmt.Printf("Using target framework: %s\n", selectedFramework)
+	result := &ExportResult{PackageID: name, Version: packageVersion, Profile: run.profile, Framework: selection}
 
 	// 複製 DLL
-	err = os.MkdirAll(filepath.Join(pluginPath, "Runtime"), os.ModePerm)
+	err := os.MkdirAll(runtimePath, os.ModePerm)
 	if err != nil {
-		return fmt.Errorf("Creation of the plugin directory failed: %v", err)
+		return nil, fmt.Errorf("Creation of the plugin directory failed: %v", err)
+	}
+
+	// 使用者明確要求的 root 套件即使由 Unity 內建也照常匯出（deny 中的 root 已由 checkRootDenied 拒絕）；
+	// 只略過 root 本身的排除，root 中的其他組件仍依 filter 排除
+	rootFilter := filter
+	if filter.Excludes(root.ID) {
+		warning := fmt.Sprintf("%s is %s; exporting it anyway because it was requested explicitly", root.ID, filter.Reason(root.ID))
+		progress.Warnf(report, progress.StageCopy, "%s", warning)
+		result.Warnings = append(result.Warnings, warning)
+		rootFilter = filter.WithAllowed(root.ID)
+	}
+	var dllName, asmName string
+	totalCopied := 0
+	if selectedFramework != "" {
+		dllName, asmName, totalCopied, err = nuget.CopyDlls(root.ID, root.InstallDir, selectedFramework, runtimePath, rootFilter, report)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Packages = append(result.Packages, PackageResult{ID: root.ID, Version: packageVersion, Framework: selectedFramework, Copied: totalCopied})
+	for _, pkg := range deps {
+		pkgResult, err := copyDependencyDlls(pkg, run.targets, runtimePath, filter, report)
+		if err != nil {
+			return nil, err
+		}
+		result.Packages = append(result.Packages, pkgResult)
+		totalCopied += pkgResult.Copied
+	}
+
+	// 複製 runtimes/<rid>/lib 下的 RID 專用組件與 runtimes/<rid>/native 下的 native plugin，各自只在對應平台啟用
+	basePlugin := unitypackage.DefaultPluginSettings()
+	if run.opts.PluginSettings != nil {
+		basePlugin = *run.opts.PluginSettings
+	}
+	packages := append([]*nuget.ResolvedPackage{root}, deps...)
+	plugins := map[string]unitypackage.PluginSettings{}
+	for i, pkg := range packages {
+		pkgFilter := filter
+		if i == 0 {
+			pkgFilter = rootFilter
+		}
+		variants, err := copyRuntimeAssemblies(pkg, run.targets, pluginPath, pkgFilter, basePlugin, plugins, report)
+		if err != nil {
+			return nil, err
+		}
+		result.Packages[i].Copied += variants
+		totalCopied += variants
+
+		native, err := copyNativeAssets(pkg, pluginPath, basePlugin, plugins, report)
+		if err != nil {
+			return nil, err
+		}
+		result.Packages[i].Native = native
 	}
 
-	dllName, asmName, totalCopied, err := nuget.CopyDlls(packageInstallDir, selectedFramework, filepath.Join(pluginPath, "Runtime"))
+	// 複製 Roslyn analyzer 與 source generator
+	if !run.opts.SkipAnalyzers {
+		if roslyn, ok := run.profile.RoslynVersion(); ok {
+			for i, pkg := range packages {
+				analyzers, err := copyAnalyzers(pkg, run.analyzerLanguage, roslyn, pluginPath, plugins, report)
+				if err != nil {
+					return nil, err
+				}
+				result.Packages[i].Analyzers = analyzers
+			}
+		} else {
+			progress.Infof(report, progress.StageCopy, "Skipping Roslyn analyzers: %s does not support them", run.profile)
+		}
+	}
+
+	// 建立 package.json；.unitypackage 匯入至 Assets/，Unity 不讀取其中的 package.json，
+	// 因此版本無法以 UPM 表示時只略過，UPM 格式則匯出失敗
+	err = packagemanifest.CreatePackageJson(name, packageVersion, run.profile.UnityVersion, upmDependencies, pluginPath)
+	if errors.Is(err, packagemanifest.ErrRevisionVersion) && run.format == FormatUnityPackage {
+		warning := fmt.Sprintf("Skipping package.json: %v", err)
+		progress.Warnf(report, progress.StageCopy, "%s", warning)
+		result.Warnings = append(result.Warnings, warning)
+	} else if err != nil {
+		return nil, fmt.Errorf("Error creating package.json: %w", err)
+	}
+
+	// 讀取匯出組件的 metadata 並檢查
+	assemblies, warnings, err := inspectAssemblies(pluginPath, run.profile, run.targets, report)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	result.Assemblies = assemblies
+	result.Warnings = append(result.Warnings, warnings...)
 
-	// 建立 package.json
-	err = packagemanifest.CreatePackageJson(nugetPackageName, packageVersion, pluginPath)
+	result.Files, err = listFiles(pluginPath)
 	if err != nil {
-		return fmt.Errorf("Error creating package.json: %v", err)
+		return nil, err
 	}
 
-	// 建立 asmdef
-	if asmName != "" && dllName != "" {
-		err = packagemanifest.CreateAsmdef(asmName, dllName, filepath.Join(pluginPath, "Runtime"))
-		if err != nil {
-			return fmt.Errorf("Error creating asmdef: %v", err)
+	progress.Infof(report, progress.StageCopy, "========== Script finished, copied [%d] DLL(s) from '%s' to %s! ==========", totalCopied, selectedFramework, pluginPath)
+	return &stagedPackage{name: name, dir: pluginPath, result: result, packages: packages, plugins: plugins, asmName: asmName, asmdefDLL: dllName}, nil
+}
+
+// writeGeneratedFiles 在打包前為每個套件建立 asmdef 與 link.xml（衝突處理後才確定匯出的組件），
+// 並更新檔案清單；回傳 writeLinkXML 的警告
+func (run *exportRun) writeGeneratedFiles(staged []*stagedPackage) ([]string, error) {
+	for _, s := range staged {
+		if err := run.writeAsmdef(s); err != nil {
+			return nil, err
+		}
+	}
+	warnings, err := run.writeLinkXML(staged)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range staged {
+		if s.result.Files, err = listFiles(s.dir); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// writeAsmdef 在 Runtime/ 建立以 root 主要組件命名的 asmdef，precompiledReferences 為主要 DLL 與 Runtime/ 下其他的 .NET 組件
+func (run *exportRun) writeAsmdef(s *stagedPackage) error {
+	if s.asmName == "" || s.asmdefDLL == "" {
+		return nil
+	}
+	dllNames := []string{s.asmdefDLL}
+	seen := map[string]bool{strings.ToLower(s.asmdefDLL): true}
+	for _, assembly := range s.result.Assemblies {
+		// Runtime/<平台> 下的 RID 專用組件只在部分平台啟用，且通常與 Runtime/ 下的組件同名
+		if path.Dir(assembly.Path) != "Runtime" {
+			continue
+		}
+		name := path.Base(assembly.Path)
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			dllNames = append(dllNames, name)
 		}
-		fmt.Printf("Created asmdef for: %s\n", asmName)
 	}
 
-	fmt.Printf("\n========== Script finished, copied [%d] DLL(s) from '%s' to %s! ==========\n", totalCopied, selectedFramework, pluginPath)
-	fmt.Println("Now creating .unitypackage without using Unity...")
+	var settings packagemanifest.AsmdefSettings
+	if run.opts.Asmdef != nil {
+		settings = *run.opts.Asmdef
+	}
+	fileName, err := packagemanifest.CreateAsmdef(packagemanifest.NewAsmdef(s.asmName, dllNames, settings), filepath.Join(s.dir, "Runtime"))
+	if err != nil {
+		return fmt.Errorf("Error creating asmdef: %v", err)
+	}
+	s.result.Asmdef = path.Join("Runtime", fileName)
+	progress.Infof(run.report, progress.StagePack, "Created asmdef for: %s (%d precompiled reference(s))", s.asmName, len(dllNames))
+	return nil
+}
+
+func (run *exportRun) packOptions(staged *stagedPackage) unitypackage.PackOptions {
+	return unitypackage.PackOptions{
+		PreviousGUIDs: run.previousGUIDs,
+		Plugins:       staged.plugins,
+		DefaultPlugin: run.opts.PluginSettings,
+	}
+}
 
-	unityPackageName := nugetPackageName + ".unitypackage"
-	err = unitypackage.CreateUnityPackageFromExport(pluginPath, nugetPackageName, unityPackageName)
+// pack 依格式將 staged 打包至 OutputDir（資料夾格式只寫入 .meta）
+func (run *exportRun) pack(staged *stagedPackage) error {
+	report := run.report
+	name, pluginPath, result := staged.name, staged.dir, staged.result
+	if err := os.MkdirAll(run.outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	packOptions := run.packOptions(staged)
+	var err error
+	switch run.format {
+	case FormatFolder:
+		progress.Infof(report, progress.StagePack, "Writing .meta files to %s...", pluginPath)
+		result.ArtifactPath = pluginPath
+		err = unitypackage.WriteFolderMetas(pluginPath, name, packagemanifest.PackageName(name), packOptions)
+	case FormatTarball:
+		progress.Infof(report, progress.StagePack, "Now creating UPM package tarball...")
+		upmName := packagemanifest.PackageName(name)
+		var upmVersion string
+		if upmVersion, err = packagemanifest.UPMVersion(result.Version); err != nil {
+			return err
+		}
+		result.ArtifactPath = filepath.Join(run.outputDir, upmName+"-"+upmVersion+".tgz")
+		err = unitypackage.CreateUPMTarball(pluginPath, name, upmName, result.ArtifactPath, packOptions)
+	default:
+		progress.Infof(report, progress.StagePack, "Now creating .unitypackage without using Unity...")
+		result.ArtifactPath = filepath.Join(run.outputDir, name+".unitypackage")
+		err = unitypackage.CreateUnityPackage(result.ArtifactPath, run.assetRoot, []unitypackage.PackageDir{{Dir: pluginPath, Name: name, Options: packOptions}})
+	}
 	if err != nil {
-		return fmt.Errorf("Error creating unitypackage: %v", err)
+		return fmt.Errorf("Error creating %s: %v", run.format, err)
 	}
 
-	fmt.Printf("Unitypackage '%s' created successfully!\n", unityPackageName)
+	progress.Infof(report, progress.StageDone, "Package '%s' created successfully!", result.ArtifactPath)
+	return nil
+}
+
+// removePreviousExport 移除 dir 中前一次以資料夾格式輸出的套件；dir 存在但不是匯出結果（沒有 package.json）時回傳錯誤
+func removePreviousExport(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "package.json")); err != nil {
+		return fmt.Errorf("%s already exists and is not an exported package; remove it or choose another output directory", dir)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove previous export %s: %v", dir, err)
+	}
 	return nil
 }
+
+// copyDependencyDlls 為依賴套件選擇框架並將其 DLL 與 root 一起複製
+func copyDependencyDlls(pkg *nuget.ResolvedPackage, targets []nuget.Framework, runtimePath string, filter *unity.AssemblyFilter, report progress.Reporter) (PackageResult, error) {
+	pkgResult := PackageResult{ID: pkg.ID, Version: pkg.Version.String()}
+	frameworkDirs, err := nuget.ListFrameworks(pkg.InstallDir)
+	if err != nil {
+		return pkgResult, err
+	}
+	if len(frameworkDirs) == 0 {
+		progress.Infof(report, progress.StageCopy, "Dependency %s %s has no assemblies, skipped", pkg.ID, pkg.Version)
+		return pkgResult, nil
+	}
+
+	framework, err := nuget.ChooseFramework(frameworkDirs, targets)
+	if err != nil {
+		return pkgResult, fmt.Errorf("dependency %s %s: %w", pkg.ID, pkg.Version, err)
+	}
+	_, _, copied, err := nuget.CopyDlls(pkg.ID, pkg.InstallDir, framework, runtimePath, filter, report)
+	if err != nil {
+		return pkgResult, err
+	}
+	progress.Infof(report, progress.StageCopy, "Dependency %s %s: copied [%d] DLL(s) from '%s'", pkg.ID, pkg.Version, copied, framework)
+	pkgResult.Framework = framework
+	pkgResult.Copied = copied
+	return pkgResult, nil
+}
+
+// copyRuntimeAssemblies 為套件 runtimes/<rid>/lib/<tfm> 下的 RID 專用組件選擇框架並複製到 Runtime/<平台>，
+// 這些組件只在對應平台啟用；lib/ 下的同名組件保留為其他平台與 Editor 的 fallback
+func copyRuntimeAssemblies(pkg *nuget.ResolvedPackage, targets []nuget.Framework, pluginPath string, filter *unity.AssemblyFilter,
+	base unitypackage.PluginSettings, plugins map[string]unitypackage.PluginSettings, report progress.Reporter) (int, error) {
+	runtimes, err := nuget.ListRuntimeAssets(pkg.InstallDir)
+	if err != nil {
+		return 0, err
+	}
+	// 含架構的 RID（win-x64）優先於不含架構的 RID（win），避免同名組件在同一平台重複啟用
+	sort.SliceStable(runtimes, func(i, j int) bool {
+		return strings.Contains(runtimes[i].RID, "-") && !strings.Contains(runtimes[j].RID, "-")
+	})
+
+	copied := 0
+	claimed := platformClaims{}
+	replaced := map[string]platformClaims{} // lib/ 組件檔名 → 由 RID 專用組件取代的平台與 CPU
+	for _, runtime := range runtimes {
+		if len(runtime.Frameworks) == 0 {
+			continue
+		}
+		platform, ok := unitypackage.PlatformForRID(runtime.RID)
+		if !ok {
+			progress.Infof(report, progress.StageCopy, "Skipping runtime-specific assemblies of %s for %s: not a Unity platform", pkg.ID, runtime.RID)
+			continue
+		}
+		var platformTargets []unitypackage.PlatformSetting
+		for _, target := range platform.Targets {
+			// Editor 一律使用 lib/ 的組件
+			if target.Target == unitypackage.TargetEditor {
+				continue
+			}
+			if target, ok := claimed.unclaimed(target); ok {
+				platformTargets = append(platformTargets, target)
+			}
+		}
+		if len(platformTargets) == 0 {
+			progress.Infof(report, progress.StageCopy, "Skipping runtime-specific assemblies of %s for %s: its platforms are covered by a more specific runtime", pkg.ID, runtime.RID)
+			continue
+		}
+		selection, err := nuget.SelectFramework(runtime.Frameworks, targets)
+		if err != nil {
+			progress.Infof(report, progress.StageCopy, "Skipping runtime-specific assemblies of %s for %s: %v", pkg.ID, runtime.RID, err)
+			continue
+		}
+
+		folder := path.Join("Runtime", platform.Folder)
+		names, err := runtime.CopyDlls(selection.Folder, filepath.Join(pluginPath, filepath.FromSlash(folder)), filter, report)
+		if err != nil {
+			return copied, err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		settings := base.Restrict(platformTargets)
+		for _, name := range names {
+			plugins[path.Join(folder, name)] = settings
+			if replaced[name] == nil {
+				replaced[name] = platformClaims{}
+			}
+			for _, target := range platformTargets {
+				replaced[name].claim(target)
+			}
+		}
+		for _, target := range platformTargets {
+			claimed.claim(target)
+		}
+		copied += len(names)
+		progress.Infof(report, progress.StageCopy, "Copied [%d] runtime-specific DLL(s) of %s for %s from '%s' to %s", len(names), pkg.ID, runtime.RID, selection.Folder, folder)
+	}
+
+	for name, claims := range replaced {
+		plugins[path.Join("Runtime", name)] = claims.fallback(base)
+	}
+	return copied, nil
+}
+
+// platformClaims 記錄已由 RID 專用組件啟用的平台與 CPU；只有一種 CPU 的平台以空字串為 CPU
+type platformClaims map[unitypackage.BuildTarget]map[string]bool
+
+// platformCPUs 回傳 setting 涵蓋的 CPU：未指定或 AnyCPU 時為平台所有的 CPU
+func platformCPUs(setting unitypackage.PlatformSetting) []string {
+	all := unitypackage.TargetCPUs(setting.Target)
+	if len(all) == 0 {
+		return []string{""}
+	}
+	if setting.CPU == "" || setting.CPU == "AnyCPU" {
+		return all
+	}
+	return []string{setting.CPU}
+}
+
+func (c platformClaims) claim(setting unitypackage.PlatformSetting) {
+	if c[setting.Target] == nil {
+		c[setting.Target] = map[string]bool{}
+	}
+	for _, cpu := range platformCPUs(setting) {
+		c[setting.Target][cpu] = true
+	}
+}
+
+// remaining 回傳 target 尚未被取代的 CPU
+func (c platformClaims) remaining(target unitypackage.BuildTarget) []string {
+	var cpus []string
+	for _, cpu := range platformCPUs(unitypackage.PlatformSetting{Target: target}) {
+		if !c[target][cpu] {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
+// unclaimed 回傳 setting 中尚未被取代的部分；部分 CPU 已被取代時只在剩下的單一 CPU 啟用，
+// 剩下多個 CPU 則無法以 PluginImporter 表示，回傳 false
+func (c platformClaims) unclaimed(setting unitypackage.PlatformSetting) (unitypackage.PlatformSetting, bool) {
+	cpus := platformCPUs(setting)
+	var free []string
+	for _, cpu := range cpus {
+		if !c[setting.Target][cpu] {
+			free = append(free, cpu)
+		}
+	}
+	switch {
+	case len(free) == len(cpus):
+		return setting, true
+	case len(free) == 1:
+		setting.CPU = free[0]
+		return setting, true
+	}
+	return setting, false
+}
+
+// fallback 回傳 lib/ 組件的設定：所有 CPU 都被取代的平台停用，只剩一個 CPU 的平台只在該 CPU 啟用
+func (c platformClaims) fallback(base unitypackage.PluginSettings) unitypackage.PluginSettings {
+	settings := base
+	var excluded []unitypackage.BuildTarget
+	targets := make([]unitypackage.BuildTarget, 0, len(c))
+	for target := range c {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	for _, target := range targets {
+		remaining := c.remaining(target)
+		switch {
+		case len(remaining) == 0:
+			excluded = append(excluded, target)
+		case len(remaining) == 1 && remaining[0] != "":
+			settings = settings.WithCPU(target, remaining[0])
+		}
+	}
+	return settings.Without(excluded...)
+}
+
+// copyAnalyzers 將套件中適用於 Unity Roslyn 版本的 analyzer 複製到 Analyzers/，並標記為 RoslynAnalyzer
+func copyAnalyzers(pkg *nuget.ResolvedPackage, language string, roslyn unity.Version, pluginPath string, plugins map[string]unitypackage.PluginSettings, report progress.Reporter) (int, error) {
+	selection, err := nuget.SelectAnalyzers(pkg.InstallDir, language, roslyn)
+	if err != nil {
+		return 0, err
+	}
+	if len(selection.Files) == 0 {
+		return 0, nil
+	}
+
+	names, err := selection.CopyAnalyzers(filepath.Join(pluginPath, "Analyzers"))
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range names {
+		plugins[path.Join("Analyzers", name)] = unitypackage.AnalyzerSettings()
+	}
+	source := "analyzers/dotnet"
+	if selection.Roslyn != "" {
+		source += "/" + selection.Roslyn
+	}
+	progress.Infof(report, progress.StageCopy, "Copied [%d] Roslyn analyzer(s) of %s from '%s' (%s) to Analyzers", len(names), pkg.ID, source, language)
+	return len(names), nil
+}
+
+// copyNativeAssets 將套件 runtimes/<rid>/native 下的檔案複製到 Plugins/<平台>/<架構>，
+// 並在 plugins 中記錄每個檔案（與 .bundle、.framework 等資料夾）的設定：沿用 base，但只在該平台啟用
+func copyNativeAssets(pkg *nuget.ResolvedPackage, pluginPath string, base unitypackage.PluginSettings, plugins map[string]unitypackage.PluginSettings, report progress.Reporter) (int, error) {
+	runtimes, err := nuget.ListRuntimeAssets(pkg.InstallDir)
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for _, runtime := range runtimes {
+		if len(runtime.NativeFiles) == 0 {
+			continue
+		}
+		platform, ok := unitypackage.PlatformForRID(runtime.RID)
+		if !ok {
+			progress.Infof(report, progress.StageCopy, "Skipping native assets of %s for %s: not a Unity platform", pkg.ID, runtime.RID)
+			continue
+		}
+
+		folder := path.Join("Plugins", platform.Folder)
+		if err := runtime.CopyNative(filepath.Join(pluginPath, filepath.FromSlash(folder))); err != nil {
+			return copied, err
+		}
+		settings := base.Restrict(platform.Targets)
+		for _, rel := range runtime.NativeFiles {
+			plugins[path.Join(folder, rel)] = settings
+			for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+				plugins[path.Join(folder, dir)] = settings
+			}
+		}
+		copied += len(runtime.NativeFiles)
+		progress.Infof(report, progress.StageCopy, "Copied [%d] native file(s) of %s for %s to %s", len(runtime.NativeFiles), pkg.ID, runtime.RID, folder)
+	}
+	return copied, nil
+}
+
+// listFiles 回傳 dir 下所有檔案以 "/" 分隔的相對路徑
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exported files: %v", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unitypackage"
+)
+
+// copyRuntimeFixture 建立含 runtimes/<rid>/lib/netstandard2.0/Foo.dll 的套件並執行 copyRuntimeAssemblies
+func copyRuntimeFixture(t *testing.T, rids ...string) map[string]unitypackage.PluginSettings {
+	t.Helper()
+	installDir := t.TempDir()
+	for _, rid := range rids {
+		dir := filepath.Join(installDir, "runtimes", rid, "lib", "netstandard2.0")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "Foo.dll"), []byte(rid), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plugins := map[string]unitypackage.PluginSettings{}
+	pkg := &nuget.ResolvedPackage{ID: "Foo", InstallDir: installDir}
+	copied, err := copyRuntimeAssemblies(pkg, nuget.ProfileTargets(unity.DefaultProfile), t.TempDir(), nil,
+		unitypackage.DefaultPluginSettings(), plugins, progress.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != len(rids) {
+		t.Errorf("copied %d assemblies, want %d", copied, len(rids))
+	}
+	return plugins
+}
+
+func assertOnlyEnabled(t *testing.T, plugins map[string]unitypackage.PluginSettings, file string, want unitypackage.PlatformSetting) {
+	t.Helper()
+	settings, ok := plugins[file]
+	if !ok {
+		t.Fatalf("%s has no plugin settings", file)
+	}
+	if settings.AnyPlatform || !reflect.DeepEqual(settings.Include, []unitypackage.PlatformSetting{want}) {
+		t.Errorf("%s: AnyPlatform %v, Include %+v; want only %+v", file, settings.AnyPlatform, settings.Include, want)
+	}
+}
+
+func TestCopyRuntimeAssembliesWindowsArchitectures(t *testing.T) {
+	plugins := copyRuntimeFixture(t, "win-arm64", "win-x64", "win-x86")
+
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/arm64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin64, CPU: "ARM64"})
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/x86_64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin64, CPU: "x86_64"})
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/x86/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin, CPU: "x86"})
+
+	// 兩種 Win64 CPU 與 Win 都已取代，lib/ 的組件只留給其他平台與 Editor
+	fallback := plugins["Runtime/Foo.dll"]
+	if !fallback.AnyPlatform || len(fallback.Include) != 0 {
+		t.Errorf("fallback: AnyPlatform %v, Include %+v; want every platform", fallback.AnyPlatform, fallback.Include)
+	}
+	want := []unitypackage.BuildTarget{unitypackage.TargetWin, unitypackage.TargetWin64}
+	if !reflect.DeepEqual(fallback.Exclude, want) {
+		t.Errorf("fallback excludes %v, want %v", fallback.Exclude, want)
+	}
+}
+
+func TestCopyRuntimeAssembliesMacArchitectures(t *testing.T) {
+	plugins := copyRuntimeFixture(t, "osx-arm64", "osx-x64")
+
+	assertOnlyEnabled(t, plugins, "Runtime/macOS/arm64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetOSXUniversal, CPU: "ARM64"})
+	assertOnlyEnabled(t, plugins, "Runtime/macOS/x86_64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetOSXUniversal, CPU: "x86_64"})
+	want := []unitypackage.BuildTarget{unitypackage.TargetOSXUniversal}
+	if got := plugins["Runtime/Foo.dll"].Exclude; !reflect.DeepEqual(got, want) {
+		t.Errorf("fallback excludes %v, want %v", got, want)
+	}
+}
+
+func TestCopyRuntimeAssembliesPartialArchitectures(t *testing.T) {
+	// 只有 win-arm64 時，x64 的 Windows player 仍使用 lib/ 的組件
+	plugins := copyRuntimeFixture(t, "win-arm64")
+
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/arm64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin64, CPU: "ARM64"})
+	fallback := plugins["Runtime/Foo.dll"]
+	if len(fallback.Exclude) != 0 {
+		t.Errorf("fallback excludes %v, want none", fallback.Exclude)
+	}
+	want := []unitypackage.PlatformSetting{{Target: unitypackage.TargetWin64, CPU: "x86_64"}}
+	if !fallback.AnyPlatform || !reflect.DeepEqual(fallback.Include, want) {
+		t.Errorf("fallback: AnyPlatform %v, Include %+v; want every platform with %+v", fallback.AnyPlatform, fallback.Include, want)
+	}
+}
+
+func TestCopyRuntimeAssembliesGenericRID(t *testing.T) {
+	// win-x64 優先，win 只補上還沒有組件的 Win（x86）
+	plugins := copyRuntimeFixture(t, "win", "win-x64")
+
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/x86_64/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin64, CPU: "x86_64"})
+	assertOnlyEnabled(t, plugins, "Runtime/Windows/Foo.dll", unitypackage.PlatformSetting{Target: unitypackage.TargetWin, CPU: "x86"})
+}
+
+func TestCopyNativeAssetsKeepsPluginSettings(t *testing.T) {
+	installDir := t.TempDir()
+	for _, rel := range []string{"runtimes/win-x64/native/foo.dll", "runtimes/android-arm64/native/libfoo.so"} {
+		path := filepath.Join(installDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("native"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 使用者的設定：preload、define constraints，且停用 Android
+	base, err := unitypackage.NewPluginSettings(nil, []string{"Android"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base.IsPreloaded = true
+	base.DefineConstraints = []string{"FOO_NATIVE"}
+
+	plugins := map[string]unitypackage.PluginSettings{}
+	pkg := &nuget.ResolvedPackage{ID: "Foo", InstallDir: installDir}
+	copied, err := copyNativeAssets(pkg, t.TempDir(), base, plugins, progress.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied != 2 {
+		t.Errorf("copied %d native files, want 2", copied)
+	}
+
+	windows := plugins["Plugins/Windows/x86_64/foo.dll"]
+	if !windows.IsPreloaded || !reflect.DeepEqual(windows.DefineConstraints, base.DefineConstraints) {
+		t.Errorf("Windows plugin = %+v, want the base preload and define constraints", windows)
+	}
+	if windows.AnyPlatform || len(windows.Include) == 0 {
+		t.Errorf("Windows plugin: AnyPlatform %v, Include %+v; want only its platform", windows.AnyPlatform, windows.Include)
+	}
+	for _, setting := range windows.Include {
+		if setting.Target != unitypackage.TargetWin64 && setting.Target != unitypackage.TargetEditor {
+			t.Errorf("Windows plugin is enabled for %+v", setting)
+		}
+	}
+	// 使用者停用的平台不會因 native plugin 而啟用
+	android, ok := plugins["Plugins/Android/arm64-v8a/libfoo.so"]
+	if !ok {
+		t.Fatalf("Android plugin has no settings: %v", plugins)
+	}
+	if android.AnyPlatform || len(android.Include) != 0 {
+		t.Errorf("Android plugin: AnyPlatform %v, Include %+v; want no platform", android.AnyPlatform, android.Include)
+	}
+}
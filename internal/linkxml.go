@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// parsePreserves 解析 "組件" 或 "組件/命名空間" 的清單
+func parsePreserves(entries []string) ([]unity.Preserve, error) {
+	var preserves []unity.Preserve
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		p, err := unity.ParsePreserve(entry)
+		if err != nil {
+			return nil, err
+		}
+		preserves = append(preserves, p)
+	}
+	return preserves, nil
+}
+
+// writeLinkXML 在每個套件的 Runtime/ 產生 link.xml，保留其中匯出的組件：opts.LinkXML 時為 unity.KnownPreserves，
+// 另加上 opts.Preserve 與套件自己的 preserve（"*" 為所有匯出的組件）。UPM 格式另建立 Editor/ 的 linker processor。回傳沒有對應任何匯出組件的 opts.Preserve 的警告
+func (run *exportRun) writeLinkXML(staged []*stagedPackage) ([]string, error) {
+	matched := map[string]bool{}
+	for _, s := range staged {
+		if !run.opts.LinkXML && len(run.preserves) == 0 && len(s.preserves) == 0 {
+			continue
+		}
+		exported := map[string]string{} // 小寫名稱 → 組件名稱
+		var names []string
+		for _, assembly := range s.result.Assemblies {
+			key := strings.ToLower(assembly.Name)
+			if _, ok := exported[key]; !ok {
+				exported[key] = assembly.Name
+				names = append(names, assembly.Name)
+			}
+		}
+
+		var preserves []unity.Preserve
+		if run.opts.LinkXML {
+			preserves = unity.KnownPreserves(names)
+		}
+		add := func(p unity.Preserve) bool {
+			if p.Assembly == "*" {
+				for _, name := range names {
+					preserves = append(preserves, unity.Preserve{Assembly: name})
+				}
+				return true
+			}
+			name, ok := exported[strings.ToLower(p.Assembly)]
+			if ok {
+				p.Assembly = name
+				preserves = append(preserves, p)
+			}
+			return ok
+		}
+		for _, p := range run.preserves {
+			if add(p) {
+				matched[p.String()] = true
+			}
+		}
+		for _, p := range s.preserves {
+			if !add(p) && p.Assembly != "*" {
+				warning := fmt.Sprintf("Cannot preserve %s: %s does not export that assembly", p, s.name)
+				progress.Warnf(run.report, progress.StagePack, "%s", warning)
+				s.result.Warnings = append(s.result.Warnings, warning)
+			}
+		}
+		if len(preserves) == 0 {
+			progress.Infof(run.report, progress.StagePack, "No assembly of %s needs a link.xml", s.name)
+			continue
+		}
+
+		if err := packagemanifest.CreateLinkXML(preserves, filepath.Join(s.dir, "Runtime")); err != nil {
+			return nil, fmt.Errorf("Error creating link.xml: %v", err)
+		}
+		s.result.LinkXML = path.Join("Runtime", packagemanifest.LinkXMLName)
+		s.result.Preserved = preserves
+		progress.Infof(run.report, progress.StagePack, "Created %s preserving %d entry(s) for IL2CPP", s.result.LinkXML, len(preserves))
+
+		// UnityLinker 不讀取 UPM 套件中的 link.xml，改由 Editor 的 IUnityLinkerProcessor 在建置時加入
+		if run.format != FormatUnityPackage {
+			if err := packagemanifest.CreateLinkerProcessor(packagemanifest.PackageName(s.name), s.dir); err != nil {
+				return nil, fmt.Errorf("Error creating link.xml processor: %v", err)
+			}
+			progress.Infof(run.report, progress.StagePack, "Created %s/ with an IUnityLinkerProcessor that adds the link.xml to UPM builds", packagemanifest.LinkerProcessorDir)
+		}
+	}
+
+	var warnings []string
+	for _, p := range run.preserves {
+		if p.Assembly != "*" && !matched[p.String()] {
+			warning := fmt.Sprintf("Cannot preserve %s: no exported assembly has that name", p)
+			progress.Warnf(run.report, progress.StagePack, "%s", warning)
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, nil
+}
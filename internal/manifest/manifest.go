@@ -0,0 +1,239 @@
+// Package manifest 讀取批次匯出的套件清單，支援 JSON Lines、YAML、packages.config
+// 以及 Directory.Packages.props 等 MSBuild 專案檔。
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// Package 為清單中要匯出的一個套件
+type Package struct {
+	ID         string   `json:"id"`
+	Version    string   `json:"version,omitempty"`    // 精確版本、範圍或浮動版本，空字串為最新版
+	Framework  string   `json:"framework,omitempty"`  // 指定使用的 lib/ 資料夾，空字串自動選擇
+	Prerelease bool     `json:"prerelease,omitempty"` // 允許預覽版
+	Exclude    []string `json:"exclude,omitempty"`    // 此套件不匯出的依賴或組件
+	Preserve   []string `json:"preserve,omitempty"`   // 寫入 link.xml 的組件或 "組件/命名空間"
+}
+
+// Load 讀取 path 的套件清單
+func Load(path string) ([]Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return packages, nil
+}
+
+// Parse 依內容判斷格式並解析套件清單：以 "<" 開頭為 packages.config 或 MSBuild 專案檔，
+// 以 "{" 或 "[" 開頭為 JSON Lines（或 JSON 陣列），其他為 YAML
+func Parse(data []byte) ([]Package, error) {
+	var packages []Package
+	var err error
+	switch firstChar(data) {
+	case '<':
+		packages, err = parseXML(data)
+	case '{', '[':
+		packages, err = parseJSON(data)
+	default:
+		packages, err = parseYAML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return packages, Validate(packages)
+}
+
+// Validate 檢查每個套件都有 id、有效的版本與 preserve，且沒有重複
+func Validate(packages []Package) error {
+	if len(packages) == 0 {
+		return fmt.Errorf("the manifest lists no packages")
+	}
+	seen := map[string]bool{}
+	for _, p := range packages {
+		if p.ID == "" {
+			return fmt.Errorf("a package has no id")
+		}
+		key := strings.ToLower(p.ID)
+		if seen[key] {
+			return fmt.Errorf("%s is listed more than once", p.ID)
+		}
+		seen[key] = true
+		if _, err := nuget.ParseVersionRange(p.Version); err != nil {
+			return fmt.Errorf("%s has an invalid version %q: %v", p.ID, p.Version, err)
+		}
+		for _, entry := range p.Preserve {
+			if _, err := unity.ParsePreserve(entry); err != nil {
+				return fmt.Errorf("%s: %v", p.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// firstChar 回傳第一個不是空白、也不在註解行（# 或 //）中的字元
+func firstChar(data []byte) byte {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimPrefix(line, "\ufeff")
+		if line == "" || isComment(line) {
+			continue
+		}
+		return line[0]
+	}
+	return 0
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//")
+}
+
+// parseJSON 解析每行一個物件的 JSON Lines，空行與 # 或 // 開頭的註解行會被忽略；
+// 內容以 "[" 開頭時視為物件陣列
+func parseJSON(data []byte) ([]Package, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var packages []Package
+		if err := decodeStrict(trimmed, &packages); err != nil {
+			return nil, err
+		}
+		return packages, nil
+	}
+
+	var packages []Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || isComment(line) {
+			continue
+		}
+		var p Package
+		if err := decodeStrict([]byte(line), &p); err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+		packages = append(packages, p)
+	}
+	return packages, scanner.Err()
+}
+
+// decodeStrict 解碼 JSON，不接受未知的欄位
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// parseXML 依根元素解析 packages.config（<packages>）或 MSBuild 專案檔（<Project>）
+func parseXML(data []byte) ([]Package, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	switch root.XMLName.Local {
+	case "packages":
+		return parsePackagesConfig(data)
+	case "Project":
+		return parseMSBuild(data)
+	default:
+		return nil, fmt.Errorf("unsupported XML manifest <%s>: expected packages.config or an MSBuild project", root.XMLName.Local)
+	}
+}
+
+// parsePackagesConfig 解析 packages.config；其中的版本為已安裝的版本，以精確版本匯出
+func parsePackagesConfig(data []byte) ([]Package, error) {
+	var config struct {
+		Packages []struct {
+			ID      string `xml:"id,attr"`
+			Version string `xml:"version,attr"`
+		} `xml:"package"`
+	}
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	var packages []Package
+	for _, p := range config.Packages {
+		version := p.Version
+		if version != "" {
+			version = "[" + version + "]"
+		}
+		packages = append(packages, Package{ID: p.ID, Version: version})
+	}
+	return packages, nil
+}
+
+type msbuildItem struct {
+	XMLName         xml.Name
+	Include         string `xml:"Include,attr"`
+	Update          string `xml:"Update,attr"`
+	Version         string `xml:"Version,attr"`
+	VersionOverride string `xml:"VersionOverride,attr"`
+	VersionElement  string `xml:"Version"`
+}
+
+// parseMSBuild 解析 MSBuild 專案檔中的 PackageReference、GlobalPackageReference 與 PackageVersion。
+// 有 PackageReference 時匯出這些參照，沒有版本者取用 PackageVersion（Central Package Management）
+// 或 <PackageReference Update> 的版本；否則（如 Directory.Packages.props）匯出所有 PackageVersion
+func parseMSBuild(data []byte) ([]Package, error) {
+	var project struct {
+		ItemGroups []struct {
+			Items []msbuildItem `xml:",any"`
+		} `xml:"ItemGroup"`
+	}
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+
+	var references, versions []Package
+	central := map[string]string{}
+	for _, group := range project.ItemGroups {
+		for _, item := range group.Items {
+			id := strings.TrimSpace(item.Include)
+			version := item.VersionOverride
+			if version == "" {
+				version = item.Version
+			}
+			if version == "" {
+				version = item.VersionElement
+			}
+			version = strings.TrimSpace(version)
+
+			switch item.XMLName.Local {
+			case "PackageReference", "GlobalPackageReference":
+				if id == "" {
+					central[strings.ToLower(strings.TrimSpace(item.Update))] = version
+					continue
+				}
+				references = append(references, Package{ID: id, Version: version})
+			case "PackageVersion":
+				versions = append(versions, Package{ID: id, Version: version})
+				central[strings.ToLower(id)] = version
+			}
+		}
+	}
+	if len(references) == 0 {
+		return versions, nil
+	}
+	for i, p := range references {
+		if p.Version == "" {
+			references[i].Version = central[strings.ToLower(p.ID)]
+		}
+	}
+	return references, nil
+}
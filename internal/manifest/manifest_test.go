@@ -0,0 +1,250 @@
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Package
+	}{
+		{
+			name: "indented packages",
+			data: `
+packages:
+  - id: Newtonsoft.Json
+    version: 13.0.3
+    exclude: [System.Memory, System.Buffers]
+    preserve:
+      - Newtonsoft.Json
+  - Serilog
+`,
+			want: []Package{
+				{ID: "Newtonsoft.Json", Version: "13.0.3", Exclude: []string{"System.Memory", "System.Buffers"}, Preserve: []string{"Newtonsoft.Json"}},
+				{ID: "Serilog"},
+			},
+		},
+		{
+			name: "unindented packages",
+			data: `packages:
+- id: Serilog
+  prerelease: true
+  exclude:
+  - System.Memory
+  - System.Buffers
+- id: Dapper
+`,
+			want: []Package{
+				{ID: "Serilog", Prerelease: true, Exclude: []string{"System.Memory", "System.Buffers"}},
+				{ID: "Dapper"},
+			},
+		},
+		{
+			name: "top-level sequence",
+			data: "---\n- Serilog\n- id: Dapper\n  framework: netstandard2.0\n",
+			want: []Package{{ID: "Serilog"}, {ID: "Dapper", Framework: "netstandard2.0"}},
+		},
+		{
+			name: "comments",
+			data: `# 清單
+packages: # 匯出的套件
+  - id: Serilog # 記錄
+    version: "[2.12.0]" # 精確版本
+    exclude: [System.Memory] # 依賴
+  # - id: Dapper
+  - id: Foo#Bar
+`,
+			want: []Package{
+				{ID: "Serilog", Version: "[2.12.0]", Exclude: []string{"System.Memory"}},
+				{ID: "Foo#Bar"},
+			},
+		},
+		{
+			name: "quoting",
+			data: `- id: "Serilog"
+  version: '2.*'
+  preserve: ["Serilog/Serilog.Core", 'Serilog/Serilog''s']
+- "Dapper # not a comment"
+`,
+			want: []Package{
+				{ID: "Serilog", Version: "2.*", Preserve: []string{"Serilog/Serilog.Core", "Serilog/Serilog's"}},
+				{ID: "Dapper # not a comment"},
+			},
+		},
+		{
+			name: "empty flow list",
+			data: "- id: Serilog\n  exclude: []\n",
+			want: []Package{{ID: "Serilog"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"tab indentation", "- id: Serilog\n\tversion: 1.0.0\n", "line 2: tabs are not allowed"},
+		{"unknown key", "- id: Serilog\n  versoin: 1.0.0\n", `line 2: unknown key "versoin"`},
+		{"top-level key", "name: foo\n", `line 1: unknown key "name"`},
+		{"not a mapping", "packages:\n  - id: Serilog\n    just text\n", "line 3: expected"},
+		{"item indentation", "packages:\n  - id: Serilog\n    - id: Dapper\n", "line 3: unexpected indentation"},
+		{"unterminated string", "\n# comment\n- id: \"Serilog\n", "line 3: unterminated string"},
+		{"prerelease", "- id: Serilog\n  prerelease: maybe\n", "line 2: prerelease must be true or false"},
+		{"no id", "- version: 1.0.0\n", "a package has no id"},
+		{"duplicate", "- Serilog\n- serilog\n", "serilog is listed more than once"},
+		{"invalid version", "- id: Serilog\n  version: not-a-version\n", "Serilog has an invalid version"},
+		{"empty", "# nothing\n", "the manifest lists no packages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Package
+	}{
+		{
+			name: "lines",
+			data: "// 清單\n{\"id\": \"Serilog\", \"version\": \"2.12.0\"}\n\n# 註解\n{\"id\": \"Dapper\", \"exclude\": [\"System.Memory\"]}\n",
+			want: []Package{{ID: "Serilog", Version: "2.12.0"}, {ID: "Dapper", Exclude: []string{"System.Memory"}}},
+		},
+		{
+			name: "array",
+			data: "[\n  {\"id\": \"Serilog\"},\n  {\"id\": \"Dapper\", \"prerelease\": true}\n]\n",
+			want: []Package{{ID: "Serilog"}, {ID: "Dapper", Prerelease: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := Parse([]byte("{\"id\": \"Serilog\"}\n{\"id\": \"Dapper\", \"versoin\": \"1.0.0\"}\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("unknown field error = %v, want line 2", err)
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Package
+	}{
+		{
+			name: "packages.config",
+			data: `<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="Newtonsoft.Json" version="13.0.3" targetFramework="net472" />
+  <package id="Serilog" version="2.12.0" />
+</packages>`,
+			want: []Package{{ID: "Newtonsoft.Json", Version: "[13.0.3]"}, {ID: "Serilog", Version: "[2.12.0]"}},
+		},
+		{
+			name: "PackageReference",
+			data: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageReference Include="Serilog">
+      <Version>2.12.0</Version>
+    </PackageReference>
+    <ProjectReference Include="..\Other\Other.csproj" />
+  </ItemGroup>
+</Project>`,
+			want: []Package{{ID: "Newtonsoft.Json", Version: "13.0.3"}, {ID: "Serilog", Version: "2.12.0"}},
+		},
+		{
+			name: "central package management",
+			data: `<Project>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Serilog" Version="2.12.0" />
+    <PackageVersion Include="Dapper" Version="2.1.0" />
+  </ItemGroup>
+  <ItemGroup>
+    <PackageReference Include="newtonsoft.json" />
+    <PackageReference Include="Serilog" VersionOverride="3.0.1" />
+    <GlobalPackageReference Include="Dapper" />
+  </ItemGroup>
+</Project>`,
+			want: []Package{
+				{ID: "newtonsoft.json", Version: "13.0.3"},
+				{ID: "Serilog", Version: "3.0.1"},
+				{ID: "Dapper", Version: "2.1.0"},
+			},
+		},
+		{
+			name: "PackageReference Update",
+			data: `<Project>
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="Dapper" Version="2.1.0" />
+  </ItemGroup>
+  <ItemGroup>
+    <PackageReference Update="Serilog" Version="2.12.0" />
+  </ItemGroup>
+</Project>`,
+			want: []Package{{ID: "Serilog", Version: "2.12.0"}, {ID: "Dapper", Version: "2.1.0"}},
+		},
+		{
+			name: "Directory.Packages.props",
+			data: `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Serilog" Version="[2.12.0, 3.0.0)" />
+  </ItemGroup>
+</Project>`,
+			want: []Package{{ID: "Newtonsoft.Json", Version: "13.0.3"}, {ID: "Serilog", Version: "[2.12.0, 3.0.0)"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("<configuration />")); err == nil || !strings.Contains(err.Error(), "unsupported XML manifest <configuration>") {
+		t.Errorf("Parse(<configuration>) error = %v", err)
+	}
+}
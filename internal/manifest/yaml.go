@@ -0,0 +1,177 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML 解析 YAML 清單的子集：頂層（或 packages: 下）為套件序列，每個套件是由
+// id、version、framework、prerelease、exclude 與 preserve 組成的 mapping，或只有 id 的純量；
+// exclude 與 preserve 可寫成 [a, b] 或下一層的序列
+//
+//	packages:
+//	  - id: Newtonsoft.Json
+//	    version: 13.0.3
+//	    exclude: [System.Memory]
+//	    preserve:
+//	      - Newtonsoft.Json
+//	  - Serilog
+func parseYAML(data []byte) ([]Package, error) {
+	var packages []Package
+	itemIndent := -1
+	list := "" // 正在讀取下一層序列的 key（exclude 或 preserve）
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	for number := 1; scanner.Scan(); number++ {
+		raw := stripYAMLComment(scanner.Text())
+		text := strings.TrimSpace(raw)
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(raw, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", number)
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		var err error
+
+		switch {
+		case text == "-" || strings.HasPrefix(text, "- "):
+			rest := strings.TrimSpace(text[1:])
+			if list != "" && indent > itemIndent {
+				err = appendListItem(&packages[len(packages)-1], list, rest)
+				break
+			}
+			if len(packages) > 0 && indent != itemIndent {
+				err = fmt.Errorf("unexpected indentation")
+				break
+			}
+			packages = append(packages, Package{})
+			itemIndent = indent
+			list = ""
+			if key, value, ok := splitYAMLKey(rest); ok {
+				list, err = setYAMLField(&packages[len(packages)-1], key, value)
+			} else if rest != "" {
+				packages[len(packages)-1].ID, err = yamlScalar(rest)
+			}
+		default:
+			key, value, ok := splitYAMLKey(text)
+			switch {
+			case !ok:
+				err = fmt.Errorf("expected \"key: value\" or a \"- \" list item")
+			case len(packages) == 0 && key == "packages" && value == "":
+			case len(packages) == 0 || indent <= itemIndent:
+				err = fmt.Errorf("unknown key %q", key)
+			default:
+				list, err = setYAMLField(&packages[len(packages)-1], key, value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+	}
+	return packages, scanner.Err()
+}
+
+// setYAMLField 設定 p 的欄位；exclude 或 preserve 的值在下一層的序列中時回傳該 key
+func setYAMLField(p *Package, key, value string) (string, error) {
+	if key == "exclude" || key == "preserve" {
+		if value == "" {
+			return key, nil
+		}
+		if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+			return "", appendListItem(p, key, value)
+		}
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if err := appendListItem(p, key, item); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+
+	scalar, err := yamlScalar(value)
+	if err != nil {
+		return "", err
+	}
+	switch key {
+	case "id":
+		p.ID = scalar
+	case "version":
+		p.Version = scalar
+	case "framework":
+		p.Framework = scalar
+	case "prerelease":
+		switch strings.ToLower(scalar) {
+		case "true", "yes", "on":
+			p.Prerelease = true
+		case "false", "no", "off", "":
+			p.Prerelease = false
+		default:
+			return "", fmt.Errorf("prerelease must be true or false, got %q", scalar)
+		}
+	default:
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+	return "", nil
+}
+
+// appendListItem 將 value 加入 p 的 exclude 或 preserve
+func appendListItem(p *Package, key, value string) error {
+	item, err := yamlScalar(value)
+	if err != nil || item == "" {
+		return err
+	}
+	if key == "preserve" {
+		p.Preserve = append(p.Preserve, item)
+	} else {
+		p.Exclude = append(p.Exclude, item)
+	}
+	return nil
+}
+
+// splitYAMLKey 將 "key: value" 分成 key 與 value
+func splitYAMLKey(text string) (key, value string, ok bool) {
+	i := strings.Index(text, ":")
+	if i <= 0 || (i+1 < len(text) && text[i+1] != ' ') || strings.ContainsAny(text[:i], "\"'[{") {
+		return "", "", false
+	}
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+}
+
+// yamlScalar 解析純量，去除單引號或雙引號
+func yamlScalar(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strconv.Unquote(s)
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	if strings.HasPrefix(s, "\"") || strings.HasPrefix(s, "'") {
+		return "", fmt.Errorf("unterminated string %s", s)
+	}
+	return s, nil
+}
+
+// stripYAMLComment 移除不在引號內、位於行首或空白之後的 # 註解
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
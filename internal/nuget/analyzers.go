@@ -0,0 +1,86 @@
+package nuget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// AnalyzerSelection 為套件 analyzers/ 下選中的 Roslyn analyzer 與 source generator
+type AnalyzerSelection struct {
+	Roslyn string   // 選中的 roslynX.Y 資料夾，套件沒有依 Roslyn 版本分資料夾時為空字串
+	Files  []string // DLL 的完整路徑
+}
+
+// SelectAnalyzers 依 NuGet 的 analyzers/dotnet/[roslynX.Y/][language/] 結構選出適用的 analyzer：
+// 套件有 roslynX.Y 資料夾時選擇不高於 compiler 的最高版本，否則使用未分版本的資料夾；
+// 每個資料夾取語言無關的 DLL 與 language（cs 或 vb）子資料夾下的 DLL
+func SelectAnalyzers(packageInstallDir, language string, compiler unity.Version) (AnalyzerSelection, error) {
+	var selection AnalyzerSelection
+	dotnetPath := filepath.Join(packageInstallDir, "analyzers", "dotnet")
+	entries, err := os.ReadDir(dotnetPath)
+	if os.IsNotExist(err) {
+		return selection, nil
+	}
+	if err != nil {
+		return selection, err
+	}
+
+	var best *unity.Version
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if !entry.IsDir() || !strings.HasPrefix(name, "roslyn") {
+			continue
+		}
+		v, err := unity.ParseVersion(strings.TrimPrefix(name, "roslyn"))
+		if err != nil {
+			continue
+		}
+		if compiler.AtLeast(v) && (best == nil || v.AtLeast(*best)) {
+			version := v
+			best = &version
+			selection.Roslyn = entry.Name()
+		}
+	}
+	if selection.Roslyn == "" && hasRoslynFolders(entries) {
+		// 所有版本都需要比 Unity 更新的編譯器
+		return selection, nil
+	}
+
+	dir := filepath.Join(dotnetPath, selection.Roslyn)
+	for _, sub := range []string{dir, filepath.Join(dir, language)} {
+		files, err := filepath.Glob(filepath.Join(sub, "*.dll"))
+		if err != nil {
+			return selection, err
+		}
+		selection.Files = append(selection.Files, files...)
+	}
+	sort.Strings(selection.Files)
+	return selection, nil
+}
+
+func hasRoslynFolders(entries []os.DirEntry) bool {
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(strings.ToLower(entry.Name()), "roslyn") {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyAnalyzers 將選中的 analyzer 複製到 destPath，回傳複製的檔名
+func (a AnalyzerSelection) CopyAnalyzers(destPath string) ([]string, error) {
+	var copied []string
+	for _, file := range a.Files {
+		name := filepath.Base(file)
+		if err := copyFile(file, filepath.Join(destPath, name)); err != nil {
+			return copied, fmt.Errorf("failed to copy analyzer %s: %v", name, err)
+		}
+		copied = append(copied, name)
+	}
+	return copied, nil
+}
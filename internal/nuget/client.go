@@ -0,0 +1,286 @@
+package nuget
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+)
+
+// DefaultSource 為 nuget.org 的 V3 service index
+const DefaultSource = "https://api.nuget.org/v3/index.json"
+
+// service index 中的資源類型
+const (
+	packageBaseAddressType = "PackageBaseAddress/3.0.0"
+	searchQueryServiceType = "SearchQueryService"
+)
+
+// Client 為純 Go 實作的 NuGet V3 client，不依賴 nuget CLI 或 Mono
+type Client struct {
+	SourceURL  string
+	HTTPClient *http.Client
+
+	mu        sync.Mutex
+	resources []serviceResource
+}
+
+type serviceIndex struct {
+	Version   string            `json:"version"`
+	Resources []serviceResource `json:"resources"`
+}
+
+type serviceResource struct {
+	ID   string `json:"@id"`
+	Type string `json:"@type"`
+}
+
+// NewClient 建立指向 sourceURL 的 client，sourceURL 為空時使用 nuget.org
+func NewClient(sourceURL string) *Client {
+	if sourceURL == "" {
+		sourceURL = DefaultSource
+	}
+	return &Client{SourceURL: sourceURL, HTTPClient: http.DefaultClient}
+}
+
+// DefaultClient 依環境變數 NUGET_SOURCE 建立 client
+func DefaultClient() *Client {
+	return NewClient(os.Getenv("NUGET_SOURCE"))
+}
+
+// resource 讀取 service index（只讀一次）並回傳第一個類型為 resourceType（或其帶版本的變體）的資源位址
+func (c *Client) resource(resourceType string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resources == nil {
+		var index serviceIndex
+		if err := c.getJSON(c.SourceURL, &index); err != nil {
+			return "", fmt.Errorf("failed to read service index %s: %v", c.SourceURL, err)
+		}
+		c.resources = index.Resources
+	}
+	for _, res := range c.resources {
+		if res.Type == resourceType || strings.HasPrefix(res.Type, resourceType+"/") {
+			return res.ID, nil
+		}
+	}
+	return "", fmt.Errorf("service index %s has no %s resource", c.SourceURL, resourceType)
+}
+
+// PackageBaseAddress 讀取 service index 並回傳 flat container 的位址
+func (c *Client) PackageBaseAddress() (string, error) {
+	base, err := c.resource(packageBaseAddressType)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(base, "/") + "/", nil
+}
+
+// ListVersions 列出 feed 上該套件的所有版本字串
+func (c *Client) ListVersions(packageID string) ([]string, error) {
+	base, err := c.PackageBaseAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Versions []string `json:"versions"`
+	}
+	indexURL := base + url.PathEscape(strings.ToLower(packageID)) + "/index.json"
+	if err := c.getJSON(indexURL, &result); err != nil {
+		if isNotFound(err) {
+			return nil, errorOf(ErrPackageNotFound, "package %s was not found on %s", packageID, c.SourceURL)
+		}
+		return nil, fmt.Errorf("failed to list versions of %s: %v", packageID, err)
+	}
+	return result.Versions, nil
+}
+
+// DownloadNupkg 下載指定版本的 .nupkg 內容
+func (c *Client) DownloadNupkg(packageID, version string) ([]byte, error) {
+	return c.downloadNupkg(packageID, version, nil)
+}
+
+// downloadNupkg 下載 .nupkg 並向 reporter 回報下載的位元組數
+func (c *Client) downloadNupkg(packageID, version string, reporter progress.Reporter) ([]byte, error) {
+	nupkgURL, err := c.packageFileURL(packageID, version, ".nupkg")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.get(nupkgURL)
+	if isNotFound(err) {
+		return nil, errorOf(ErrPackageNotFound, "package %s %s was not found on %s", packageID, version, c.SourceURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s %s: %v", packageID, version, err)
+	}
+	defer resp.Body.Close()
+
+	event := progress.Event{Stage: progress.StageDownload, Package: packageID, Version: version}
+	if resp.ContentLength > 0 {
+		event.Total = resp.ContentLength
+	}
+	event.Message = fmt.Sprintf("Downloading %s %s", packageID, version)
+	progress.Emit(reporter, event)
+	data, err := io.ReadAll(progress.NewReader(resp.Body, reporter, event))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s %s: %v", packageID, version, err)
+	}
+	event.Message = ""
+	event.Bytes = int64(len(data))
+	event.Total = event.Bytes
+	progress.Emit(reporter, event)
+	return data, nil
+}
+
+// DownloadNuspec 只下載指定版本的 .nuspec，用於不需要整個套件的 metadata 查詢
+func (c *Client) DownloadNuspec(packageID, version string) (*Nuspec, error) {
+	nuspecURL, err := c.packageFileURL(packageID, version, ".nuspec")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.get(nuspecURL)
+	if isNotFound(err) {
+		return nil, errorOf(ErrPackageNotFound, "package %s %s was not found on %s", packageID, version, c.SourceURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the nuspec of %s %s: %v", packageID, version, err)
+	}
+	defer resp.Body.Close()
+
+	var spec Nuspec
+	if err := xml.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the nuspec of %s %s: %v", packageID, version, err)
+	}
+	return &spec, nil
+}
+
+// packageFileURL 回傳 flat container 中套件檔案（.nupkg 或 .nuspec）的位址
+func (c *Client) packageFileURL(packageID, version, ext string) (string, error) {
+	base, err := c.PackageBaseAddress()
+	if err != nil {
+		return "", err
+	}
+	lowerID := url.PathEscape(strings.ToLower(packageID))
+	lowerVersion := url.PathEscape(strings.ToLower(version))
+	if ext == ".nuspec" {
+		return base + lowerID + "/" + lowerVersion + "/" + lowerID + ext, nil
+	}
+	return base + lowerID + "/" + lowerVersion + "/" + lowerID + "." + lowerVersion + ext, nil
+}
+
+// InstallPackage 下載並解壓縮套件至 outputDir/<packageID>.<version>，回傳解壓後的目錄
+func (c *Client) InstallPackage(packageID, version, outputDir string) (string, error) {
+	return c.installPackage(packageID, version, outputDir, nil)
+}
+
+func (c *Client) installPackage(packageID, version, outputDir string, reporter progress.Reporter) (string, error) {
+	data, err := c.downloadNupkg(packageID, version, reporter)
+	if err != nil {
+		return "", err
+	}
+
+	progress.Emit(reporter, progress.Event{Stage: progress.StageExtract, Package: packageID, Version: version})
+	installDir := filepath.Join(outputDir, packageID+"."+version)
+	if err := ExtractNupkg(data, installDir); err != nil {
+		return "", fmt.Errorf("failed to extract %s %s: %v", packageID, version, err)
+	}
+
+	nupkgName := strings.ToLower(packageID + "." + version + ".nupkg")
+	if err := os.WriteFile(filepath.Join(installDir, nupkgName), data, 0644); err != nil {
+		return "", err
+	}
+	return installDir, nil
+}
+
+// ExtractNupkg 將 .nupkg (zip) 解壓縮至 destDir，略過 OPC 封裝用的檔案
+func ExtractNupkg(data []byte, destDir string) error {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range reader.File {
+		name, err := url.PathUnescape(f.Name)
+		if err != nil {
+			name = f.Name
+		}
+		name = strings.ReplaceAll(name, "\\", "/")
+		if isPackagingFile(name) || strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		dst := filepath.Join(destDir, filepath.FromSlash(name))
+		if !strings.HasPrefix(dst, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path in package: %s", f.Name)
+		}
+		if err := extractZipFile(f, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPackagingFile(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "[content_types].xml" ||
+		strings.HasPrefix(lower, "_rels/") ||
+		strings.HasPrefix(lower, "package/services/metadata/")
+}
+
+func extractZipFile(f *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
+func (c *Client) get(rawURL string) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &statusError{url: rawURL, status: resp.Status, code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+func (c *Client) getJSON(rawURL string, v interface{}) error {
+	resp, err := c.get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,154 @@
+package nuget
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testNuspec = `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Foo.Bar</id>
+    <version>1.2.0</version>
+    <authors>Test</authors>
+    <description>Test package</description>
+  </metadata>
+</package>`
+
+// newTestFeed 啟動只有 Foo.Bar 1.0.0 / 1.2.0 / 2.0.0-beta 的 V3 feed，回傳 client 與 nupkg 內容
+func newTestFeed(t *testing.T) (*Client, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Foo.Bar.nuspec":                     testNuspec,
+		"lib/netstandard2.0/Foo.Bar.dll":     "dll",
+		"[Content_Types].xml":                "<Types/>",
+		"_rels/.rels":                        "<Relationships/>",
+		"package/services/metadata/core.xml": "<core/>",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	nupkg := buf.Bytes()
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v3/index.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"3.0.0","resources":[
+			{"@id":"` + server.URL + `/search","@type":"SearchQueryService/3.5.0"},
+			{"@id":"` + server.URL + `/flat","@type":"PackageBaseAddress/3.0.0"}]}`))
+	})
+	mux.HandleFunc("/flat/foo.bar/index.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"versions":["1.0.0","1.2.0","2.0.0-beta"]}`))
+	})
+	mux.HandleFunc("/flat/foo.bar/1.2.0/foo.bar.1.2.0.nupkg", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(nupkg)
+	})
+	mux.HandleFunc("/flat/foo.bar/1.2.0/foo.bar.nuspec", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testNuspec))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL + "/v3/index.json"), nupkg
+}
+
+func TestClientPackageBaseAddress(t *testing.T) {
+	client, _ := newTestFeed(t)
+	base, err := client.PackageBaseAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := client.SourceURL[:len(client.SourceURL)-len("/v3/index.json")] + "/flat/"; base != want {
+		t.Errorf("PackageBaseAddress() = %q, want %q", base, want)
+	}
+}
+
+func TestClientListVersions(t *testing.T) {
+	client, _ := newTestFeed(t)
+	versions, err := client.ListVersions("Foo.Bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1.0.0", "1.2.0", "2.0.0-beta"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+
+	best, err := client.ResolveVersion("Foo.Bar", mustParseRange(t, "1.*"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.String() != "1.2.0" {
+		t.Errorf("ResolveVersion() = %s, want 1.2.0", best)
+	}
+	if _, err := client.ResolveVersion("Foo.Bar", mustParseRange(t, "[3.0,)"), true); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("ResolveVersion([3.0,)) error = %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestClientDownload(t *testing.T) {
+	client, nupkg := newTestFeed(t)
+	data, err := client.DownloadNupkg("Foo.Bar", "1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, nupkg) {
+		t.Error("DownloadNupkg() returned different content")
+	}
+
+	spec, err := client.DownloadNuspec("Foo.Bar", "1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Metadata.ID != "Foo.Bar" || spec.Metadata.Version != "1.2.0" {
+		t.Errorf("DownloadNuspec() = %s %s, want Foo.Bar 1.2.0", spec.Metadata.ID, spec.Metadata.Version)
+	}
+
+	installDir, err := client.InstallPackage("Foo.Bar", "1.2.0", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(installDir, "lib", "netstandard2.0", "Foo.Bar.dll")); err != nil {
+		t.Errorf("library was not extracted: %v", err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels", "package"} {
+		if _, err := os.Stat(filepath.Join(installDir, name)); !os.IsNotExist(err) {
+			t.Errorf("packaging file %s was extracted", name)
+		}
+	}
+}
+
+func TestClientNotFound(t *testing.T) {
+	client, _ := newTestFeed(t)
+	if _, err := client.ListVersions("Missing.Package"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("ListVersions() error = %v, want ErrPackageNotFound", err)
+	}
+	if _, err := client.DownloadNupkg("Foo.Bar", "9.9.9"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("DownloadNupkg() error = %v, want ErrPackageNotFound", err)
+	}
+	if _, err := client.DownloadNuspec("Foo.Bar", "9.9.9"); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("DownloadNuspec() error = %v, want ErrPackageNotFound", err)
+	}
+}
+
+func mustParseRange(t *testing.T, s string) VersionRange {
+	t.Helper()
+	r, err := ParseVersionRange(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
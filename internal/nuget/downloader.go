@@ -1,46 +1,33 @@
 package nuget
 
-import (
-	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-)
-
-// DownloadPackage 使用 nuget CLI 下載指定套件至 tempDir
-func DownloadPackage(packageName, packageVersion, tempDir string) error {
-	args := []string{"install", packageName, "-OutputDirectory", tempDir}
-	if packageVersion != "" && packageVersion != "latest" {
-		args = append(args, "-Version", packageVersion)
+// Versions 列出 feed 上該套件所有可解析的版本
+func (c *Client) Versions(packageID string) ([]Version, error) {
+	rawVersions, err := c.ListVersions(packageID)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.Command("nuget", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
-// FindInstalledPackageDir 尋找安裝後的套件目錄
-func FindInstalledPackageDir(packageName, tempDir string) (string, error) {
-	var packageInstallDir string
-	err := filepath.Walk(tempDir, func(path string, info os.FileInfo, wErr error) error {
-		if wErr != nil {
-			return wErr
+	versions := make([]Version, 0, len(rawVersions))
+	for _, raw := range rawVersions {
+		v, err := ParseVersion(raw)
+		if err != nil {
+			continue
 		}
-		if info.IsDir() && strings.HasPrefix(filepath.Base(path), packageName+".") {
-			packageInstallDir = path
-			return filepath.SkipDir
-		}
-		return nil
-	})
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
 
+// ResolveVersion 依版本範圍從 feed 的版本清單中選出最佳版本
+func (c *Client) ResolveVersion(packageID string, versionRange VersionRange, allowPrerelease bool) (Version, error) {
+	versions, err := c.Versions(packageID)
 	if err != nil {
-		return "", err
+		return Version{}, err
 	}
 
-	if packageInstallDir == "" {
-		return "", fmt.Errorf("Could not find installed package directory for %s", packageName)
+	best, ok := versionRange.FindBestMatch(versions, allowPrerelease)
+	if !ok {
+		return Version{}, errorOf(ErrVersionNotFound, "no version of %s matches %s", packageID, versionRange)
 	}
-	return packageInstallDir, nil
+	return best, nil
 }
@@ -0,0 +1,46 @@
+package nuget
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 可用 errors.Is 判斷的錯誤類別
+var (
+	// ErrPackageNotFound 表示 feed 上沒有該套件或該版本
+	ErrPackageNotFound = errors.New("package not found")
+	// ErrVersionNotFound 表示沒有符合版本範圍的版本
+	ErrVersionNotFound = errors.New("no matching version")
+	// ErrNoCompatibleFramework 表示套件沒有目標框架可用的組件
+	ErrNoCompatibleFramework = errors.New("no compatible framework")
+)
+
+// kindError 保留原本的訊息，並可用 errors.Is 判斷其類別
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// errorOf 以 format 產生屬於 kind 類別的錯誤
+func errorOf(kind error, format string, args ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), kind: kind}
+}
+
+// statusError 為 feed 回傳非 200 的 HTTP 狀態
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string { return fmt.Sprintf("GET %s: %s", e.url, e.status) }
+
+// isNotFound 判斷 err 是否為 feed 回傳的 404
+func isNotFound(err error) bool {
+	var status *statusError
+	return errors.As(err, &status) && status.code == http.StatusNotFound
+}
@@ -1,26 +1,501 @@
 package nuget
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// 框架識別碼（與 NuGet 的長名稱一致）
+const (
+	FrameworkNetFramework = ".NETFramework"
+	FrameworkNetStandard  = ".NETStandard"
+	FrameworkNetCoreApp   = ".NETCoreApp"
+	FrameworkNetCore      = ".NETCore"
+	FrameworkNetPlatform  = ".NETPlatform"
+	FrameworkPortable     = ".NETPortable"
+	FrameworkUAP          = "UAP"
+	FrameworkWindows      = "Windows"
+	FrameworkWindowsPhone = "WindowsPhone"
+	FrameworkWinPhoneApp  = "WindowsPhoneApp"
+	FrameworkSilverlight  = "Silverlight"
+	FrameworkMonoAndroid  = "MonoAndroid"
+	FrameworkMonoTouch    = "MonoTouch"
+	FrameworkMonoMac      = "MonoMac"
+	FrameworkXamarinIOS   = "Xamarin.iOS"
+	FrameworkXamarinMac   = "Xamarin.Mac"
+	FrameworkXamarinTVOS  = "Xamarin.TVOS"
+	FrameworkTizen        = "Tizen"
+	FrameworkNative       = "native"
+	FrameworkAny          = "Any"
 )
 
-// 預設的目標框架優先順序
-var frameworkPriority = []string{
-	"netstandard2.0",
-	"net45",
-	"net46",
-	"net47",
-	"net48",
-	"netstandard2.1",
+// 短名稱前綴與識別碼的對應，較長的前綴需排在前面
+var shortFrameworkIdentifiers = []struct {
+	short      string
+	identifier string
+}{
+	{"netstandard", FrameworkNetStandard},
+	{"netcoreapp", FrameworkNetCoreApp},
+	{"netcore", FrameworkNetCore},
+	{"net", FrameworkNetFramework},
+	{"dotnet", FrameworkNetPlatform},
+	{"uap", FrameworkUAP},
+	{"win", FrameworkWindows},
+	{"winrt", FrameworkWindows},
+	{"wpa", FrameworkWinPhoneApp},
+	{"wp", FrameworkWindowsPhone},
+	{"sl", FrameworkSilverlight},
+	{"monoandroid", FrameworkMonoAndroid},
+	{"monotouch", FrameworkMonoTouch},
+	{"monomac", FrameworkMonoMac},
+	{"xamarinios", FrameworkXamarinIOS},
+	{"xamarin.ios", FrameworkXamarinIOS},
+	{"xamarinmac", FrameworkXamarinMac},
+	{"xamarin.mac", FrameworkXamarinMac},
+	{"xamarintvos", FrameworkXamarinTVOS},
+	{"tizen", FrameworkTizen},
+	{"native", FrameworkNative},
+}
+
+// 長名稱（nuspec 的 targetFramework 常見寫法）與識別碼的對應
+var longFrameworkIdentifiers = map[string]string{
+	".netframework":   FrameworkNetFramework,
+	".netstandard":    FrameworkNetStandard,
+	".netcoreapp":     FrameworkNetCoreApp,
+	".netcore":        FrameworkNetCore,
+	".netplatform":    FrameworkNetPlatform,
+	".netportable":    FrameworkPortable,
+	"windowsphoneapp": FrameworkWinPhoneApp,
+	"windowsphone":    FrameworkWindowsPhone,
+	"windows":         FrameworkWindows,
+	"silverlight":     FrameworkSilverlight,
+	"monoandroid":     FrameworkMonoAndroid,
+	"monotouch":       FrameworkMonoTouch,
+	"xamarin.ios":     FrameworkXamarinIOS,
+	"xamarin.mac":     FrameworkXamarinMac,
+	"uap":             FrameworkUAP,
+	"tizen":           FrameworkTizen,
+}
+
+// Framework 為解析後的 target framework moniker (TFM)
+type Framework struct {
+	Identifier      string
+	Version         Version
+	Profile         string // .NETFramework 的 "Client" 或 portable 的成員清單 (如 "net45+win8")
+	Platform        string // net5.0 以後的 OS 平台，如 "windows"、"android"
+	PlatformVersion Version
+}
+
+// AnyFramework 為不限框架的資產（例如 lib/ 根目錄的 DLL）
+var AnyFramework = Framework{Identifier: FrameworkAny}
+
+// ParseFramework 解析資料夾短名稱（"netstandard2.0"、"net45"、"net6.0-windows10.0"、
+// "portable-net45+win8"）或長名稱（".NETStandard2.0"、".NETFramework,Version=v4.5"）
+func ParseFramework(name string) (Framework, error) {
+	s := strings.TrimSpace(name)
+	lower := strings.ToLower(s)
+	if lower == "" || lower == "any" {
+		return AnyFramework, nil
+	}
+	if strings.Contains(s, ",") {
+		return parseLongFrameworkName(s)
+	}
+	if strings.HasPrefix(lower, "portable") {
+		return parsePortableFramework(lower)
+	}
+	for long, identifier := range longFrameworkIdentifiers {
+		if strings.HasPrefix(lower, long) && (len(lower) == len(long) || isDigit(lower[len(long)])) {
+			return frameworkWithVersion(identifier, lower[len(long):], name)
+		}
+	}
+	return parseShortFrameworkName(lower, name)
+}
+
+// MustParseFramework 同 ParseFramework，解析失敗時 panic，僅用於常數
+func MustParseFramework(name string) Framework {
+	fw, err := ParseFramework(name)
+	if err != nil {
+		panic(err)
+	}
+	return fw
+}
+
+func parseShortFrameworkName(lower, original string) (Framework, error) {
+	main, platform := lower, ""
+	if i := strings.Index(lower, "-"); i >= 0 {
+		main, platform = lower[:i], lower[i+1:]
+	}
+
+	for _, entry := range shortFrameworkIdentifiers {
+		if !strings.HasPrefix(main, entry.short) {
+			continue
+		}
+		rest := main[len(entry.short):]
+		if rest != "" && !isDigit(rest[0]) {
+			continue
+		}
+		fw, err := frameworkWithVersion(entry.identifier, rest, original)
+		if err != nil {
+			return Framework{}, err
+		}
+
+		// net5.0 之後的 "net" 為 .NETCoreApp
+		if fw.Identifier == FrameworkNetFramework && fw.Version.Major >= 5 {
+			fw.Identifier = FrameworkNetCoreApp
+		}
+		if platform != "" {
+			if fw.Identifier == FrameworkNetCoreApp && fw.Version.Major >= 5 {
+				if err := fw.setPlatform(platform); err != nil {
+					return Framework{}, fmt.Errorf("invalid target framework %q: %v", original, err)
+				}
+			} else {
+				// 舊式 profile，例如 net40-client
+				fw.Profile = platform
+			}
+		}
+		return fw, nil
+	}
+	return Framework{}, fmt.Errorf("unknown target framework %q", original)
+}
+
+func (fw *Framework) setPlatform(platform string) error {
+	i := 0
+	for i < len(platform) && !isDigit(platform[i]) {
+		i++
+	}
+	fw.Platform = platform[:i]
+	if i < len(platform) {
+		v, err := ParseVersion(platform[i:])
+		if err != nil {
+			return err
+		}
+		fw.PlatformVersion = v
+	}
+	return nil
+}
+
+// parseLongFrameworkName 解析 ".NETFramework,Version=v4.5,Profile=Client"
+func parseLongFrameworkName(s string) (Framework, error) {
+	parts := strings.Split(s, ",")
+	identifier := strings.TrimSpace(parts[0])
+	if known, ok := longFrameworkIdentifiers[strings.ToLower(identifier)]; ok {
+		identifier = known
+	}
+	fw := Framework{Identifier: identifier}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return Framework{}, fmt.Errorf("invalid target framework %q", s)
+		}
+		switch strings.ToLower(kv[0]) {
+		case "version":
+			v, err := ParseVersion(strings.TrimPrefix(strings.TrimPrefix(kv[1], "v"), "V"))
+			if err != nil {
+				return Framework{}, fmt.Errorf("invalid target framework %q: %v", s, err)
+			}
+			fw.Version = v
+		case "profile":
+			fw.Profile = kv[1]
+		}
+	}
+	if fw.Identifier == FrameworkPortable && fw.Profile != "" {
+		fw.Profile = strings.ToLower(fw.Profile)
+	}
+	return fw, nil
+}
+
+// parsePortableFramework 解析 "portable-net45+win8+wpa81" 與 "portable40-net40+sl5"
+func parsePortableFramework(lower string) (Framework, error) {
+	fw := Framework{Identifier: FrameworkPortable}
+	i := strings.Index(lower, "-")
+	if i < 0 {
+		return Framework{}, fmt.Errorf("invalid portable framework %q", lower)
+	}
+	if version := lower[len("portable"):i]; version != "" {
+		v, err := parseFrameworkVersion(version)
+		if err != nil {
+			return Framework{}, fmt.Errorf("invalid portable framework %q", lower)
+		}
+		fw.Version = v
+	}
+	fw.Profile = lower[i+1:]
+	for _, member := range fw.PortableFrameworks() {
+		if member.Identifier == "" {
+			return Framework{}, fmt.Errorf("invalid portable framework %q", lower)
+		}
+	}
+	return fw, nil
+}
+
+func frameworkWithVersion(identifier, version, original string) (Framework, error) {
+	fw := Framework{Identifier: identifier}
+	if version == "" {
+		return fw, nil
+	}
+	v, err := parseFrameworkVersion(version)
+	if err != nil {
+		return Framework{}, fmt.Errorf("invalid target framework %q", original)
+	}
+	fw.Version = v
+	return fw, nil
+}
+
+// parseFrameworkVersion 解析 "4.5"、"2.0"，或無點號時每一位數字為一段（"45" 為 4.5、"462" 為 4.6.2）
+func parseFrameworkVersion(s string) (Version, error) {
+	if strings.Contains(s, ".") {
+		return ParseVersion(s)
+	}
+	if len(s) > 4 {
+		return Version{}, fmt.Errorf("invalid framework version %q", s)
+	}
+	nums := make([]int, 4)
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return Version{}, fmt.Errorf("invalid framework version %q", s)
+		}
+		nums[i], _ = strconv.Atoi(s[i : i+1])
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Revision: nums[3]}, nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// IsAny 是否為不限框架
+func (fw Framework) IsAny() bool {
+	return fw.Identifier == FrameworkAny
+}
+
+// PortableFrameworks 回傳 portable profile 的成員框架
+func (fw Framework) PortableFrameworks() []Framework {
+	if fw.Identifier != FrameworkPortable || fw.Profile == "" {
+		return nil
+	}
+	var members []Framework
+	for _, part := range strings.Split(fw.Profile, "+") {
+		member, err := parseShortFrameworkName(part, part)
+		if err != nil {
+			// 未知成員（如 xamarinwatchos）不影響其餘成員
+			continue
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// ShortFolderName 回傳 NuGet 資料夾短名稱
+func (fw Framework) ShortFolderName() string {
+	switch fw.Identifier {
+	case FrameworkAny:
+		return "any"
+	case FrameworkPortable:
+		return "portable-" + fw.Profile
+	}
+
+	short := fw.Identifier
+	for _, entry := range shortFrameworkIdentifiers {
+		if entry.identifier == fw.Identifier {
+			short = entry.short
+			break
+		}
+	}
+	if fw.Identifier == FrameworkNetCoreApp && fw.Version.Major >= 5 {
+		short = "net"
+	}
+
+	var version string
+	dotted := strings.HasPrefix(short, "net") || fw.Identifier == FrameworkUAP || fw.Identifier == FrameworkTizen
+	if fw.Identifier == FrameworkNetFramework || !dotted {
+		// net45、net462、win81 等以無點號的形式表示
+		version = strconv.Itoa(fw.Version.Major) + strconv.Itoa(fw.Version.Minor)
+		if fw.Version.Patch > 0 || fw.Version.Revision > 0 {
+			version += strconv.Itoa(fw.Version.Patch)
+		}
+		if fw.Version.Revision > 0 {
+			version += strconv.Itoa(fw.Version.Revision)
+		}
+	} else {
+		version = fmt.Sprintf("%d.%d", fw.Version.Major, fw.Version.Minor)
+	}
+
+	name := short + version
+	if fw.Platform != "" {
+		name += "-" + fw.Platform
+		if fw.PlatformVersion.Compare(Version{}) != 0 {
+			name += fmt.Sprintf("%d.%d", fw.PlatformVersion.Major, fw.PlatformVersion.Minor)
+		}
+	} else if fw.Profile != "" {
+		name += "-" + strings.ToLower(fw.Profile)
+	}
+	return name
+}
+
+func (fw Framework) String() string {
+	return fw.ShortFolderName()
+}
+
+// netStandardSupport 列出各框架版本最高可使用的 .NET Standard 版本
+var netStandardSupport = map[string][]struct {
+	since       Version
+	netStandard Version
+}{
+	FrameworkNetFramework: {
+		{Version{Major: 4, Minor: 5}, Version{Major: 1, Minor: 1}},
+		{Version{Major: 4, Minor: 5, Patch: 1}, Version{Major: 1, Minor: 2}},
+		{Version{Major: 4, Minor: 6}, Version{Major: 1, Minor: 3}},
+		{Version{Major: 4, Minor: 6, Patch: 1}, Version{Major: 2, Minor: 0}},
+	},
+	FrameworkNetCoreApp: {
+		{Version{Major: 1, Minor: 0}, Version{Major: 1, Minor: 6}},
+		{Version{Major: 2, Minor: 0}, Version{Major: 2, Minor: 0}},
+		{Version{Major: 3, Minor: 0}, Version{Major: 2, Minor: 1}},
+	},
+	FrameworkUAP: {
+		{Version{Major: 10, Minor: 0}, Version{Major: 1, Minor: 4}},
+		{Version{Major: 10, Minor: 0, Patch: 16299}, Version{Major: 2, Minor: 0}},
+	},
+	FrameworkMonoAndroid: {
+		{Version{}, Version{Major: 2, Minor: 1}},
+	},
+	FrameworkXamarinIOS: {
+		{Version{}, Version{Major: 2, Minor: 1}},
+	},
+	FrameworkXamarinMac: {
+		{Version{}, Version{Major: 2, Minor: 1}},
+	},
+}
+
+// supportedNetStandard 回傳目標框架最高可使用的 .NET Standard 版本
+func (fw Framework) supportedNetStandard() (Version, bool) {
+	if fw.Identifier == FrameworkNetStandard {
+		return fw.Version, true
+	}
+	var best Version
+	found := false
+	for _, entry := range netStandardSupport[fw.Identifier] {
+		if fw.Version.Compare(entry.since) >= 0 {
+			best, found = entry.netStandard, true
+		}
+	}
+	return best, found
+}
+
+// IsCompatible 判斷以 target 為目標的專案能否使用 candidate 框架的資產
+func IsCompatible(target, candidate Framework) bool {
+	switch {
+	case candidate.IsAny():
+		return true
+	case candidate.Identifier == FrameworkPortable:
+		for _, member := range candidate.PortableFrameworks() {
+			if member.Identifier == target.Identifier && member.Version.Compare(target.Version) <= 0 {
+				return true
+			}
+		}
+		return false
+	case candidate.Identifier == FrameworkNetStandard:
+		supported, ok := target.supportedNetStandard()
+		return ok && candidate.Version.Compare(supported) <= 0
+	case candidate.Identifier != target.Identifier:
+		return false
+	case candidate.Version.Compare(target.Version) > 0:
+		return false
+	}
+
+	if candidate.Platform != "" {
+		if !strings.EqualFold(candidate.Platform, target.Platform) || candidate.PlatformVersion.Compare(target.PlatformVersion) > 0 {
+			return false
+		}
+	}
+	// .NETFramework 的 Client profile 可被完整框架使用，反之則否
+	if candidate.Profile != "" && !strings.EqualFold(candidate.Profile, target.Profile) {
+		return strings.EqualFold(candidate.Profile, "client") && target.Profile == ""
+	}
+	return true
+}
+
+// GetNearest 依 NuGet 的 nearest framework 規則，從 candidates 中找出最適合 target 的框架：
+// 同識別碼（平台相符者優先）的最高版本，其次為 .NET Standard 最高版本，再其次為成員最少的 portable，最後為 Any。
+func GetNearest(target Framework, candidates []Framework) (Framework, bool) {
+	i := nearestIndex(target, candidates)
+	if i < 0 {
+		return Framework{}, false
+	}
+	return candidates[i], true
+}
+
+func nearestIndex(target Framework, candidates []Framework) int {
+	best := -1
+	for i, candidate := range candidates {
+		if !IsCompatible(target, candidate) {
+			continue
+		}
+		if best < 0 || isNearer(target, candidate, candidates[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// isNearer 判斷 a 是否比 b 更接近 target（兩者皆已確認相容）
+func isNearer(target, a, b Framework) bool {
+	rankA, rankB := compatibilityRank(target, a), compatibilityRank(target, b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	if a.Identifier == FrameworkPortable {
+		return len(a.PortableFrameworks()) < len(b.PortableFrameworks())
+	}
+	if c := a.Version.Compare(b.Version); c != 0 {
+		return c > 0
+	}
+	if c := a.PlatformVersion.Compare(b.PlatformVersion); c != 0 {
+		return c > 0
+	}
+	// 相同版本時，完整框架優先於 Client profile
+	return a.Profile == "" && b.Profile != ""
+}
+
+func compatibilityRank(target, fw Framework) int {
+	switch {
+	case fw.Identifier == target.Identifier && fw.Platform != "":
+		return 0
+	case fw.Identifier == target.Identifier:
+		return 1
+	case fw.Identifier == FrameworkNetStandard:
+		return 2
+	case fw.Identifier == FrameworkPortable:
+		return 3
+	}
+	return 4
+}
+
+// ProfileTargets 將 Unity Profile 轉為依序嘗試的目標框架
+func ProfileTargets(profile unity.Profile) []Framework {
+	names := profile.TargetFrameworks()
+	targets := make([]Framework, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, MustParseFramework(name))
+	}
+	return targets
 }
 
 // ListFrameworks 列出該套件lib下的可用Framework
 func ListFrameworks(packageInstallDir string) ([]string, error) {
 	libPath := filepath.Join(packageInstallDir, "lib")
 	frameworkDirs := []string{}
+	if _, err := os.Stat(libPath); os.IsNotExist(err) {
+		// meta-package（如 NETStandard.Library）沒有 lib 目錄
+		return frameworkDirs, nil
+	}
 	err := filepath.Walk(libPath, func(path string, info os.FileInfo, wErr error) error {
 		if wErr != nil {
 			return wErr
@@ -35,44 +510,135 @@ func ListFrameworks(packageInstallDir string) ([]string, error) {
 	return frameworkDirs, err
 }
 
-// ChooseFrameworkAuto 從找到的frameworkDirs中自動選擇最合適的
-func ChooseFrameworkAuto(frameworkDirs []string) string {
-	fwSet := make(map[string]bool)
-	for _, fw := range frameworkDirs {
-		fwSet[fw] = true
+// FrameworkSelection 為框架選擇的結果與原因
+type FrameworkSelection struct {
+	Folder    string    // lib/ 下被選中的資料夾名稱
+	Framework Framework // 解析後的框架
+	Target    Framework // 與之相容的目標框架
+	Fallback  bool      // 主要目標沒有相容框架，改用 fallback 目標
+	Reason    string
+}
+
+// SelectFramework 依序對每個目標框架做 nearest framework 比對，回傳第一個有相容結果的資料夾與選擇原因
+func SelectFramework(frameworkDirs []string, targets []Framework) (FrameworkSelection, error) {
+	candidates := make([]Framework, 0, len(frameworkDirs))
+	folders := make([]string, 0, len(frameworkDirs))
+	for _, dir := range frameworkDirs {
+		fw, err := ParseFramework(dir)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, fw)
+		folders = append(folders, dir)
 	}
-	for _, preferred := range frameworkPriority {
-		if fwSet[preferred] {
-			return preferred
+
+	for i, target := range targets {
+		nearest := nearestIndex(target, candidates)
+		if nearest < 0 {
+			continue
+		}
+		selection := FrameworkSelection{
+			Folder:    folders[nearest],
+			Framework: candidates[nearest],
+			Target:    target,
+			Fallback:  i > 0,
 		}
+		selection.Reason = fmt.Sprintf("%s is the nearest of [%s] compatible with %s",
+			selection.Folder, strings.Join(frameworkDirs, ", "), target.ShortFolderName())
+		if selection.Fallback {
+			selection.Reason = fmt.Sprintf("no framework is compatible with %s; %s (fallback)",
+				frameworkList(targets[:i]), selection.Reason)
+		}
+		return selection, nil
 	}
-	sort.Strings(frameworkDirs)
-	return frameworkDirs[0]
+	return FrameworkSelection{}, errorOf(ErrNoCompatibleFramework, "none of the frameworks [%s] is compatible with %s", strings.Join(frameworkDirs, ", "), frameworkList(targets))
 }
 
-// CopyDlls 複製目標框架下的DLLs至指定路徑
-func CopyDlls(packageInstallDir, selectedFramework, destPath string) (dllName, asmName string, totalCopied int, err error) {
-	frameworkDirPath := filepath.Join(packageInstallDir, "lib", selectedFramework)
-	dllFiles, err := filepath.Glob(filepath.Join(frameworkDirPath, "*.dll"))
-	if err != nil {
-		return "", "", 0, err
+// FindFramework 回傳 lib/ 下名稱或框架與 name 相同的資料夾，用於使用者明確指定的框架
+func FindFramework(frameworkDirs []string, name string) (FrameworkSelection, error) {
+	requested, parseErr := ParseFramework(name)
+	for _, dir := range frameworkDirs {
+		fw, err := ParseFramework(dir)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(dir, name) || (parseErr == nil && fw.ShortFolderName() == requested.ShortFolderName()) {
+			return FrameworkSelection{
+				Folder:    dir,
+				Framework: fw,
+				Target:    fw,
+				Reason:    fmt.Sprintf("%s was requested explicitly", dir),
+			}, nil
+		}
 	}
+	return FrameworkSelection{}, errorOf(ErrNoCompatibleFramework, "framework %s is not one of [%s]", name, strings.Join(frameworkDirs, ", "))
+}
 
-	if len(dllFiles) > 0 {
-		dllName = filepath.Base(dllFiles[0])
-		asmName = dllName[:len(dllName)-len(filepath.Ext(dllName))]
+// ChooseFramework 同 SelectFramework，只回傳資料夾名稱
+func ChooseFramework(frameworkDirs []string, targets []Framework) (string, error) {
+	selection, err := SelectFramework(frameworkDirs, targets)
+	return selection.Folder, err
+}
+
+func frameworkList(frameworks []Framework) string {
+	names := make([]string, len(frameworks))
+	for i, fw := range frameworks {
+		names[i] = fw.ShortFolderName()
+	}
+	return strings.Join(names, ", ")
+}
+
+// CopyDlls 複製套件 packageID 目標框架下的DLLs至指定路徑，略過 filter 排除的組件（filter 可為 nil）並向 reporter 回報
+func CopyDlls(packageID, packageInstallDir, selectedFramework, destPath string, filter *unity.AssemblyFilter, reporter progress.Reporter) (dllName, asmName string, totalCopied int, err error) {
+	copied, err := copyDllsFromDir(filepath.Join(packageInstallDir, "lib", selectedFramework), destPath, filter, reporter)
+	dllName, asmName = primaryAssembly(packageID, destPath, copied)
+	return dllName, asmName, len(copied), err
+}
+
+// primaryAssembly 從 copied 中選出 asmdef 參照的 DLL 並回傳其 metadata 中的組件名稱：
+// 組件名稱與套件 ID 相同（不分大小寫）者優先，其次為第一個 .NET 組件；
+// 都無法讀取（如 native DLL）時使用第一個檔案與其檔名
+func primaryAssembly(packageID, destPath string, copied []string) (dllName, asmName string) {
+	for _, name := range copied {
+		assembly, err := clrmeta.ReadFile(filepath.Join(destPath, name))
+		if err != nil || assembly.Name == "" {
+			continue
+		}
+		if strings.EqualFold(assembly.Name, packageID) {
+			return name, assembly.Name
+		}
+		if dllName == "" {
+			dllName, asmName = name, assembly.Name
+		}
+	}
+	if dllName == "" && len(copied) > 0 {
+		dllName = copied[0]
+		asmName = strings.TrimSuffix(dllName, filepath.Ext(dllName))
+	}
+	return dllName, asmName
+}
+
+// copyDllsFromDir 複製目錄下的DLLs至指定路徑，回傳複製的檔名
+func copyDllsFromDir(frameworkDirPath, destPath string, filter *unity.AssemblyFilter, reporter progress.Reporter) ([]string, error) {
+	dllFiles, err := filepath.Glob(filepath.Join(frameworkDirPath, "*.dll"))
+	if err != nil {
+		return nil, err
 	}
 
+	var copied []string
 	for _, dll := range dllFiles {
 		dllNameLocal := filepath.Base(dll)
-		dst := filepath.Join(destPath, dllNameLocal)
-		if copyErr := copyFile(dll, dst); copyErr != nil {
-			return "", "", totalCopied, copyErr
+		name := strings.TrimSuffix(dllNameLocal, filepath.Ext(dllNameLocal))
+		if filter.Excludes(name) {
+			progress.Infof(reporter, progress.StageCopy, "Skipping %s: %s", dllNameLocal, filter.Reason(name))
+			continue
 		}
-		totalCopied++
+		if copyErr := copyFile(dll, filepath.Join(destPath, dllNameLocal)); copyErr != nil {
+			return copied, copyErr
+		}
+		copied = append(copied, dllNameLocal)
 	}
-
-	return dllName, asmName, totalCopied, nil
+	return copied, nil
 }
 
 func copyFile(src, dst string) error {
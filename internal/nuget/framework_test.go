@@ -0,0 +1,45 @@
+package nuget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrimaryAssembly(t *testing.T) {
+	// 使用 clrmeta 的測試組件
+	sources := map[string]string{
+		"Fixture.Lib.dll": "../clrmeta/testdata/Fixture.Lib.dll",
+		"Fixture.App.dll": "../clrmeta/testdata/Fixture.App.dll",
+	}
+	destPath := t.TempDir()
+	for name, src := range sources {
+		if err := copyFile(src, filepath.Join(destPath, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(destPath, "native.dll"), []byte("not a PE file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		packageID string
+		copied    []string
+		dllName   string
+		asmName   string
+	}{
+		{"Fixture.Lib", []string{"Fixture.App.dll", "Fixture.Lib.dll"}, "Fixture.Lib.dll", "Fixture.Lib"},
+		{"fixture.lib", []string{"Fixture.App.dll", "Fixture.Lib.dll"}, "Fixture.Lib.dll", "Fixture.Lib"},
+		// 組件名稱只是套件 ID 的前綴時不算相同，改用第一個 .NET 組件
+		{"Fixture.Lib.Extensions", []string{"Fixture.App.dll", "Fixture.Lib.dll"}, "Fixture.App.dll", "Fixture.App"},
+		{"Fixture", []string{"native.dll", "Fixture.Lib.dll", "Fixture.App.dll"}, "Fixture.Lib.dll", "Fixture.Lib"},
+		{"Native", []string{"native.dll"}, "native.dll", "native"},
+		{"Empty", nil, "", ""},
+	}
+	for _, tt := range tests {
+		dllName, asmName := primaryAssembly(tt.packageID, destPath, tt.copied)
+		if dllName != tt.dllName || asmName != tt.asmName {
+			t.Errorf("primaryAssembly(%q, %v) = %q, %q; want %q, %q", tt.packageID, tt.copied, dllName, asmName, tt.dllName, tt.asmName)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package nuget
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Nuspec 為 .nuspec 中匯出流程需要的欄位
+type Nuspec struct {
+	Metadata NuspecMetadata `xml:"metadata"`
+}
+
+// NuspecMetadata 對應 <metadata>
+type NuspecMetadata struct {
+	ID           string             `xml:"id"`
+	Version      string             `xml:"version"`
+	Authors      string             `xml:"authors"`
+	Description  string             `xml:"description"`
+	Dependencies NuspecDependencies `xml:"dependencies"`
+}
+
+// NuspecDependencies 對應 <dependencies>，可為依框架分組或舊式的平鋪清單
+type NuspecDependencies struct {
+	Groups       []DependencyGroup `xml:"group"`
+	Dependencies []Dependency      `xml:"dependency"`
+}
+
+// DependencyGroup 對應 <group targetFramework="...">
+type DependencyGroup struct {
+	TargetFramework string       `xml:"targetFramework,attr"`
+	Dependencies    []Dependency `xml:"dependency"`
+}
+
+// Dependency 對應 <dependency id="..." version="...">
+type Dependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr"`
+}
+
+// ReadNuspec 讀取套件目錄根部的 .nuspec
+func ReadNuspec(packageInstallDir string) (*Nuspec, error) {
+	matches, err := filepath.Glob(filepath.Join(packageInstallDir, "*.nuspec"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no .nuspec found in %s", packageInstallDir)
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil, err
+	}
+	var spec Nuspec
+	if err := xml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(matches[0]), err)
+	}
+	return &spec, nil
+}
+
+// DependencyFramework 回傳 targets 中第一個有相容依賴群組的目標框架；
+// 沒有依框架分組或都不相容時回傳 targets[0]
+func (n *Nuspec) DependencyFramework(targets []Framework) Framework {
+	var groupFrameworks []Framework
+	for _, group := range n.Metadata.Dependencies.Groups {
+		if fw, err := ParseFramework(group.TargetFramework); err == nil {
+			groupFrameworks = append(groupFrameworks, fw)
+		}
+	}
+	for _, target := range targets {
+		if len(groupFrameworks) > 0 && nearestIndex(target, groupFrameworks) >= 0 {
+			return target
+		}
+	}
+	if len(targets) == 0 {
+		return AnyFramework
+	}
+	return targets[0]
+}
+
+// DependenciesFor 回傳適用於指定目標框架的依賴清單（依 nearest framework 規則選擇群組）
+func (n *Nuspec) DependenciesFor(targetFramework string) []Dependency {
+	deps := n.Metadata.Dependencies
+	if len(deps.Groups) == 0 {
+		return deps.Dependencies
+	}
+
+	target, err := ParseFramework(targetFramework)
+	if err != nil {
+		return nil
+	}
+	groupFrameworks := make([]Framework, 0, len(deps.Groups))
+	groups := make([]*DependencyGroup, 0, len(deps.Groups))
+	for i := range deps.Groups {
+		fw, err := ParseFramework(deps.Groups[i].TargetFramework)
+		if err != nil {
+			continue
+		}
+		groupFrameworks = append(groupFrameworks, fw)
+		groups = append(groups, &deps.Groups[i])
+	}
+
+	if i := nearestIndex(target, groupFrameworks); i >= 0 {
+		return groups[i].Dependencies
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package nuget
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// ResolvedPackage 為依賴圖中已決定版本並下載完成的套件
+type ResolvedPackage struct {
+	ID           string
+	Version      Version
+	Depth        int
+	InstallDir   string
+	Nuspec       *Nuspec
+	Dependencies []Dependency // 適用於 TargetFramework 的依賴
+
+	// TargetFramework 為選擇依賴群組的目標框架，空字串使用 Resolver.TargetFramework
+	TargetFramework string
+}
+
+// DependencyGraph 為 root 套件與其遞移依賴
+type DependencyGraph struct {
+	Root     *ResolvedPackage   // 第一個 root
+	Roots    []*ResolvedPackage // ResolveGraph 的所有 root
+	Packages []*ResolvedPackage // 依深度排序，開頭為各個 root
+	Excluded []string           // 被 Resolver.Filter 排除、未下載的套件 id
+	Warnings []string
+}
+
+// Resolver 依 NuGet 的 lowest-applicable-version 與 nearest-wins 規則建立依賴圖
+type Resolver struct {
+	Client          *Client
+	TargetFramework string
+	AllowPrerelease bool
+	WorkDir         string
+	Filter          *unity.AssemblyFilter // 排除 Unity 已內建或使用者拒絕的套件
+	Progress        progress.Reporter     // 接收下載與解壓縮的事件，可為 nil
+
+	versions map[string][]Version
+}
+
+// NewResolver 建立 resolver，下載的套件會解壓縮至 workDir
+func NewResolver(client *Client, workDir string) *Resolver {
+	return &Resolver{Client: client, WorkDir: workDir}
+}
+
+// Install 下載並解壓縮指定版本的套件，讀取其 nuspec
+func (r *Resolver) Install(packageID string, version Version) (*ResolvedPackage, error) {
+	installDir, err := r.Client.installPackage(packageID, version.String(), r.WorkDir, r.Progress)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ReadNuspec(installDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &ResolvedPackage{
+		ID:         packageID,
+		Version:    version,
+		InstallDir: installDir,
+		Nuspec:     spec,
+	}
+	if spec.Metadata.ID != "" {
+		pkg.ID = spec.Metadata.ID
+	}
+	return pkg, nil
+}
+
+type dependencyRequest struct {
+	id      string
+	ranges  []VersionRange
+	parents []string
+}
+
+// ResolveDependencies 以 root 為起點逐層（廣度優先）解析遞移依賴。
+// 同一套件出現在多個深度時以最接近 root 者為準 (nearest wins)；
+// 同一深度的多個需求則選擇同時滿足所有範圍的最低版本。
+func (r *Resolver) ResolveDependencies(root *ResolvedPackage) (*DependencyGraph, error) {
+	return r.ResolveGraph([]*ResolvedPackage{root})
+}
+
+// ResolveGraph 同 ResolveDependencies，但同時從多個 root 解析：所有 root 都在深度 0，
+// 共用同一個依賴圖，多個 root 共用的依賴只選擇一個版本、下載一次
+func (r *Resolver) ResolveGraph(roots []*ResolvedPackage) (*DependencyGraph, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no packages to resolve")
+	}
+	graph := &DependencyGraph{Root: roots[0], Roots: roots, Packages: append([]*ResolvedPackage{}, roots...)}
+	resolved := map[string]*ResolvedPackage{}
+	for _, root := range roots {
+		resolved[strings.ToLower(root.ID)] = root
+	}
+	excluded := map[string]bool{}
+
+	level := roots
+	for depth := 1; len(level) > 0; depth++ {
+		requests := map[string]*dependencyRequest{}
+		order := []string{}
+		for _, parent := range level {
+			targetFramework := parent.TargetFramework
+			if targetFramework == "" {
+				targetFramework = r.TargetFramework
+			}
+			parent.Dependencies = parent.Nuspec.DependenciesFor(targetFramework)
+			for _, dep := range parent.Dependencies {
+				versionRange, err := ParseVersionRange(dep.Version)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s has an invalid dependency on %s: %v", parent.ID, parent.Version, dep.ID, err)
+				}
+
+				key := strings.ToLower(dep.ID)
+				if r.Filter.Excludes(dep.ID) {
+					if !excluded[key] {
+						excluded[key] = true
+						graph.Excluded = append(graph.Excluded, dep.ID)
+					}
+					continue
+				}
+				if existing, ok := resolved[key]; ok {
+					if !versionRange.Satisfies(existing.Version) {
+						graph.Warnings = append(graph.Warnings, fmt.Sprintf(
+							"%s %s requires %s %s, but %s was selected by a nearer dependency",
+							parent.ID, parent.Version, dep.ID, versionRange.PrettyString(), existing.Version))
+					}
+					continue
+				}
+
+				req, ok := requests[key]
+				if !ok {
+					req = &dependencyRequest{id: dep.ID}
+					requests[key] = req
+					order = append(order, key)
+				}
+				req.ranges = append(req.ranges, versionRange)
+				req.parents = append(req.parents, parent.ID)
+			}
+		}
+
+		var next []*ResolvedPackage
+		for _, key := range order {
+			req := requests[key]
+			version, err := r.selectVersion(req, graph)
+			if err != nil {
+				return nil, err
+			}
+			pkg, err := r.Install(req.id, version)
+			if err != nil {
+				return nil, err
+			}
+			pkg.Depth = depth
+			resolved[key] = pkg
+			graph.Packages = append(graph.Packages, pkg)
+			next = append(next, pkg)
+		}
+		level = next
+	}
+	return graph, nil
+}
+
+// SelectVersions 以與 ResolveGraph 相同的規則為套件 parent 的 deps 中未被 Filter 排除的依賴選擇版本，
+// 不下載套件；回傳以依賴 id 為 key 的版本
+func (r *Resolver) SelectVersions(parent string, deps []Dependency) (map[string]Version, error) {
+	selected := map[string]Version{}
+	for _, dep := range deps {
+		versionRange, err := ParseVersionRange(dep.Version)
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid dependency on %s: %v", parent, dep.ID, err)
+		}
+		if r.Filter.Excludes(dep.ID) {
+			continue
+		}
+		version, err := r.selectVersion(&dependencyRequest{id: dep.ID, ranges: []VersionRange{versionRange}, parents: []string{parent}}, &DependencyGraph{})
+		if err != nil {
+			return nil, err
+		}
+		selected[dep.ID] = version
+	}
+	return selected, nil
+}
+
+// selectVersion 選擇滿足所有範圍的最低版本；若範圍互相衝突，則取各範圍最低可用版本中最高者並記錄警告
+func (r *Resolver) selectVersion(req *dependencyRequest, graph *DependencyGraph) (Version, error) {
+	versions, err := r.listVersions(req.id)
+	if err != nil {
+		return Version{}, err
+	}
+
+	var candidates []Version
+	for _, v := range versions {
+		satisfiesAll := true
+		for _, versionRange := range req.ranges {
+			if !versionRange.Satisfies(v) {
+				satisfiesAll = false
+				break
+			}
+		}
+		if satisfiesAll {
+			candidates = append(candidates, v)
+		}
+	}
+	if best, ok := (VersionRange{}).FindBestMatch(candidates, r.AllowPrerelease || anyAllowsPrerelease(req.ranges)); ok {
+		return best, nil
+	}
+
+	var highest *Version
+	for _, versionRange := range req.ranges {
+		v, ok := versionRange.FindBestMatch(versions, r.AllowPrerelease)
+		if !ok {
+			return Version{}, errorOf(ErrVersionNotFound, "no version of %s matches %s (required by %s)",
+				req.id, versionRange.PrettyString(), strings.Join(req.parents, ", "))
+		}
+		if highest == nil || v.Compare(*highest) > 0 {
+			highest = &v
+		}
+	}
+	graph.Warnings = append(graph.Warnings, fmt.Sprintf(
+		"no single version of %s satisfies all requirements from %s; using %s",
+		req.id, strings.Join(req.parents, ", "), highest))
+	return *highest, nil
+}
+
+func (r *Resolver) listVersions(packageID string) ([]Version, error) {
+	key := strings.ToLower(packageID)
+	if versions, ok := r.versions[key]; ok {
+		return versions, nil
+	}
+	versions, err := r.Client.Versions(packageID)
+	if err != nil {
+		return nil, err
+	}
+	if r.versions == nil {
+		r.versions = make(map[string][]Version)
+	}
+	r.versions[key] = versions
+	return versions, nil
+}
+
+func anyAllowsPrerelease(ranges []VersionRange) bool {
+	for _, versionRange := range ranges {
+		if versionRange.AllowsPrerelease() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package nuget
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// feedPackage 為 newPackageFeed 提供的一個套件版本；groups 的 key 為依賴群組的框架，
+// 空字串 key 為不分組的依賴
+type feedPackage struct {
+	id, version string
+	groups      map[string][]Dependency
+}
+
+// newPackageFeed 啟動提供 packages 的 V3 feed（flat container 與 nupkg）
+func newPackageFeed(t *testing.T, packages []feedPackage) *Client {
+	t.Helper()
+	versions := map[string][]string{}
+	nupkgs := map[string][]byte{}
+	for _, p := range packages {
+		id := strings.ToLower(p.id)
+		versions[id] = append(versions[id], p.version)
+		nupkgs[id+"/"+p.version] = testNupkg(t, p)
+	}
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/v3/index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version":"3.0.0","resources":[{"@id":"%s/flat","@type":"PackageBaseAddress/3.0.0"}]}`, server.URL)
+	})
+	mux.HandleFunc("/flat/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/flat/"), "/")
+		switch {
+		case len(parts) == 2 && parts[1] == "index.json" && versions[parts[0]] != nil:
+			json.NewEncoder(w).Encode(map[string][]string{"versions": versions[parts[0]]})
+		case len(parts) == 3 && nupkgs[parts[0]+"/"+parts[1]] != nil:
+			w.Write(nupkgs[parts[0]+"/"+parts[1]])
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL + "/v3/index.json")
+}
+
+func testNupkg(t *testing.T, p feedPackage) []byte {
+	t.Helper()
+	var deps strings.Builder
+	for framework, group := range p.groups {
+		if framework != "" {
+			fmt.Fprintf(&deps, `<group targetFramework="%s">`, framework)
+		}
+		for _, dep := range group {
+			fmt.Fprintf(&deps, `<dependency id="%s" version="%s" />`, dep.ID, dep.Version)
+		}
+		if framework != "" {
+			deps.WriteString(`</group>`)
+		}
+	}
+	nuspec := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<package><metadata><id>%s</id><version>%s</version><dependencies>%s</dependencies></metadata></package>`, p.id, p.version, deps.String())
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create(p.id + ".nuspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(nuspec))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func dep(id, version string) Dependency {
+	return Dependency{ID: id, Version: version}
+}
+
+// resolveTestGraph 安裝 roots 的最高版本並以 targetFramework 解析依賴圖
+func resolveTestGraph(t *testing.T, client *Client, targetFramework string, filter *unity.AssemblyFilter, roots ...string) *DependencyGraph {
+	t.Helper()
+	resolver := NewResolver(client, t.TempDir())
+	resolver.TargetFramework = targetFramework
+	resolver.Filter = filter
+	var installed []*ResolvedPackage
+	for _, id := range roots {
+		versions, err := client.Versions(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := resolver.Install(id, versions[len(versions)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		installed = append(installed, root)
+	}
+	graph, err := resolver.ResolveGraph(installed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graph
+}
+
+// graphVersions 回傳 "id version@depth" 的清單，依 graph.Packages 的順序
+func graphVersions(graph *DependencyGraph) []string {
+	var result []string
+	for _, pkg := range graph.Packages {
+		result = append(result, fmt.Sprintf("%s %s@%d", pkg.ID, pkg.Version, pkg.Depth))
+	}
+	return result
+}
+
+func TestResolveNearestWins(t *testing.T) {
+	client := newPackageFeed(t, []feedPackage{
+		{id: "App", version: "1.0.0", groups: map[string][]Dependency{"": {dep("Shared", "1.0.0"), dep("Middle", "1.0.0")}}},
+		{id: "Middle", version: "1.0.0", groups: map[string][]Dependency{"": {dep("Shared", "2.0.0"), dep("Leaf", "[1.0.0, 2.0.0)")}}},
+		{id: "Shared", version: "1.0.0"},
+		{id: "Shared", version: "2.0.0"},
+		{id: "Leaf", version: "1.0.0"},
+		{id: "Leaf", version: "1.5.0"},
+	})
+	graph := resolveTestGraph(t, client, "netstandard2.0", nil, "App")
+
+	// Shared 由較近的 App 決定為 1.0.0（最低適用版本），Middle 對 2.0.0 的需求只產生警告
+	want := []string{"App 1.0.0@0", "Shared 1.0.0@1", "Middle 1.0.0@1", "Leaf 1.0.0@2"}
+	if got := graphVersions(graph); !reflect.DeepEqual(got, want) {
+		t.Errorf("graph = %v, want %v", got, want)
+	}
+	wantWarning := "Middle 1.0.0 requires Shared [2.0.0, ), but 1.0.0 was selected by a nearer dependency"
+	if !reflect.DeepEqual(graph.Warnings, []string{wantWarning}) {
+		t.Errorf("Warnings = %q, want %q", graph.Warnings, wantWarning)
+	}
+}
+
+func TestResolveConflictingRanges(t *testing.T) {
+	feed := []feedPackage{
+		{id: "Lib", version: "1.0.0"},
+		{id: "Lib", version: "1.5.0"},
+		{id: "Lib", version: "2.0.0"},
+		{id: "Lib", version: "2.5.0"},
+	}
+	tests := []struct {
+		name        string
+		first       string // Left 與 Right 對 Lib 的範圍
+		second      string
+		want        string
+		wantWarning bool
+	}{
+		{"overlapping ranges take the lowest common version", "[1.0.0, )", "[1.5.0, 2.5.0]", "1.5.0", false},
+		{"disjoint ranges take the highest lowest match", "[1.0.0, 2.0.0)", "[2.0.0, )", "2.0.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newPackageFeed(t, append([]feedPackage{
+				{id: "Left", version: "1.0.0", groups: map[string][]Dependency{"": {dep("Lib", tt.first)}}},
+				{id: "Right", version: "1.0.0", groups: map[string][]Dependency{"": {dep("Lib", tt.second)}}},
+			}, feed...))
+			// 兩個 root 在同一深度要求 Lib
+			graph := resolveTestGraph(t, client, "netstandard2.0", nil, "Left", "Right")
+			want := []string{"Left 1.0.0@0", "Right 1.0.0@0", "Lib " + tt.want + "@1"}
+			if got := graphVersions(graph); !reflect.DeepEqual(got, want) {
+				t.Errorf("graph = %v, want %v", got, want)
+			}
+			if hasWarning := len(graph.Warnings) > 0; hasWarning != tt.wantWarning {
+				t.Errorf("Warnings = %q, want a warning: %v", graph.Warnings, tt.wantWarning)
+			}
+		})
+	}
+
+	client := newPackageFeed(t, append([]feedPackage{
+		{id: "Broken", version: "1.0.0", groups: map[string][]Dependency{"": {dep("Lib", "[3.0.0, )")}}},
+	}, feed...))
+	resolver := NewResolver(client, t.TempDir())
+	root, err := resolver.Install("Broken", MustParseVersion("1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolver.ResolveDependencies(root); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("ResolveDependencies() error = %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestResolveDependencyGroupByFramework(t *testing.T) {
+	client := newPackageFeed(t, []feedPackage{
+		{id: "Multi", version: "1.0.0", groups: map[string][]Dependency{
+			".NETFramework4.5": {dep("Legacy", "1.0.0")},
+			".NETStandard2.0":  {dep("Modern", "1.0.0"), dep("System.Memory", "4.5.0")},
+			"net8.0":           {},
+		}},
+		{id: "Legacy", version: "1.0.0"},
+		{id: "Modern", version: "1.0.0"},
+		{id: "System.Memory", version: "4.5.0"},
+	})
+	filter := unity.NewAssemblyFilter(unity.DefaultProfile, nil, []string{"System.Memory"})
+
+	tests := []struct {
+		framework string
+		want      []string
+	}{
+		{"netstandard2.1", []string{"Multi 1.0.0@0", "Modern 1.0.0@1"}},
+		// 同一家族的 .NETFramework 群組比 .NETStandard 更接近
+		{"net472", []string{"Multi 1.0.0@0", "Legacy 1.0.0@1"}},
+		{"net45", []string{"Multi 1.0.0@0", "Legacy 1.0.0@1"}},
+		{"net8.0", []string{"Multi 1.0.0@0"}},
+	}
+	for _, tt := range tests {
+		graph := resolveTestGraph(t, client, tt.framework, filter, "Multi")
+		if got := graphVersions(graph); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: graph = %v, want %v", tt.framework, got, tt.want)
+		}
+		wantExcluded := []string(nil)
+		if strings.HasPrefix(tt.framework, "netstandard") {
+			wantExcluded = []string{"System.Memory"}
+		}
+		if !reflect.DeepEqual(graph.Excluded, wantExcluded) {
+			t.Errorf("%s: Excluded = %v, want %v", tt.framework, graph.Excluded, wantExcluded)
+		}
+	}
+}
+
+func TestDependencyFramework(t *testing.T) {
+	spec := func(frameworks ...string) *Nuspec {
+		var n Nuspec
+		for _, fw := range frameworks {
+			n.Metadata.Dependencies.Groups = append(n.Metadata.Dependencies.Groups, DependencyGroup{TargetFramework: fw})
+		}
+		return &n
+	}
+	targets := []Framework{MustParseFramework("netstandard2.1"), MustParseFramework("net472")}
+
+	tests := []struct {
+		name string
+		spec *Nuspec
+		want string
+	}{
+		{"first target is compatible", spec(".NETStandard2.0", ".NETFramework4.6.1"), "netstandard2.1"},
+		{"only a later target is compatible", spec(".NETFramework4.6.1"), "net472"},
+		{"no compatible group", spec("net8.0"), "netstandard2.1"},
+		{"no groups", spec(), "netstandard2.1"},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.DependencyFramework(targets).ShortFolderName(); got != tt.want {
+			t.Errorf("%s: DependencyFramework() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := spec().DependencyFramework(nil); !reflect.DeepEqual(got, AnyFramework) {
+		t.Errorf("DependencyFramework(nil) = %v, want AnyFramework", got)
+	}
+}
+
+func TestSelectVersions(t *testing.T) {
+	client := newPackageFeed(t, []feedPackage{
+		{id: "Lib", version: "1.0.0"},
+		{id: "Lib", version: "1.2.0"},
+		{id: "Lib", version: "2.0.0-beta"},
+		{id: "Other", version: "3.0.0"},
+	})
+	resolver := NewResolver(client, t.TempDir())
+	resolver.Filter = unity.NewAssemblyFilter(unity.DefaultProfile, nil, []string{"Denied"})
+
+	got, err := resolver.SelectVersions("App", []Dependency{dep("Lib", "1.1.0"), dep("Other", "[3.0.0]"), dep("Denied", "1.0.0")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Version{"Lib": MustParseVersion("1.2.0"), "Other": MustParseVersion("3.0.0")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectVersions() = %v, want %v", got, want)
+	}
+
+	if _, err := resolver.SelectVersions("App", []Dependency{dep("Lib", "[5.0.0, )")}); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("SelectVersions(missing) error = %v, want ErrVersionNotFound", err)
+	}
+	if _, err := resolver.SelectVersions("App", []Dependency{dep("Lib", "not a range")}); err == nil {
+		t.Error("SelectVersions(invalid range) succeeded")
+	}
+}
@@ -0,0 +1,100 @@
+package nuget
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// RuntimeAssets 為 runtimes/<rid>/ 下單一 runtime identifier 的資產
+type RuntimeAssets struct {
+	RID         string
+	Dir         string   // runtimes/<rid> 的完整路徑
+	NativeFiles []string // native/ 下的檔案，相對於 native/（"/" 分隔）
+	Frameworks  []string // lib/ 下的框架資料夾，為該 RID 專用的 managed 組件
+}
+
+// NativeDir 回傳 native 檔案所在的目錄
+func (a RuntimeAssets) NativeDir() string {
+	return filepath.Join(a.Dir, "native")
+}
+
+// LibDir 回傳 RID 專用 managed 組件所在的目錄
+func (a RuntimeAssets) LibDir() string {
+	return filepath.Join(a.Dir, "lib")
+}
+
+// ListRuntimeAssets 列出套件 runtimes/ 下每個 RID 的資產，依 RID 排序；沒有 runtimes/ 時回傳空清單
+func ListRuntimeAssets(packageInstallDir string) ([]RuntimeAssets, error) {
+	runtimesPath := filepath.Join(packageInstallDir, "runtimes")
+	entries, err := os.ReadDir(runtimesPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []RuntimeAssets
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		runtime := RuntimeAssets{RID: entry.Name(), Dir: filepath.Join(runtimesPath, entry.Name())}
+		runtime.NativeFiles, err = listFiles(runtime.NativeDir())
+		if err != nil {
+			return nil, err
+		}
+		runtime.Frameworks, err = ListFrameworks(runtime.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(runtime.NativeFiles) > 0 || len(runtime.Frameworks) > 0 {
+			assets = append(assets, runtime)
+		}
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].RID < assets[j].RID })
+	return assets, nil
+}
+
+// listFiles 遞迴列出目錄下的檔案（相對路徑，"/" 分隔）；目錄不存在時回傳空清單
+func listFiles(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, wErr error) error {
+		if wErr != nil {
+			return wErr
+		}
+		// "_._" 為 NuGet 標記空資料夾的佔位檔
+		if info.IsDir() || info.Name() == "_._" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// CopyNative 將 native/ 下的檔案保留目錄結構複製到 destDir
+func (a RuntimeAssets) CopyNative(destDir string) error {
+	for _, rel := range a.NativeFiles {
+		if err := copyFile(filepath.Join(a.NativeDir(), filepath.FromSlash(rel)), filepath.Join(destDir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyDlls 複製 lib/<selectedFramework> 下的 DLL 至 destPath，略過 filter 排除的組件，回傳複製的檔名
+func (a RuntimeAssets) CopyDlls(selectedFramework, destPath string, filter *unity.AssemblyFilter, reporter progress.Reporter) ([]string, error) {
+	return copyDllsFromDir(filepath.Join(a.LibDir(), selectedFramework), destPath, filter, reporter)
+}
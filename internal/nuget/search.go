@@ -0,0 +1,70 @@
+package nuget
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SearchResult 為 SearchQueryService 的查詢結果
+type SearchResult struct {
+	TotalHits int             `json:"totalHits"`
+	Data      []SearchPackage `json:"data"`
+}
+
+// SearchPackage 為查詢結果中的單一套件
+type SearchPackage struct {
+	ID          string        `json:"id"`
+	Version     string        `json:"version"` // 最新版本
+	Description string        `json:"description"`
+	Authors     stringList    `json:"authors"`
+	Tags        stringList    `json:"tags"`
+	ProjectURL  string        `json:"projectUrl"`
+	Versions    []SearchEntry `json:"versions"`
+}
+
+// SearchEntry 為查詢結果中套件的一個版本
+type SearchEntry struct {
+	Version string `json:"version"`
+}
+
+// stringList 接受字串或字串陣列（不同 feed 對 authors、tags 的格式不一致）
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	if single != "" {
+		*l = []string{single}
+	}
+	return nil
+}
+
+// Search 以 SearchQueryService 搜尋套件，skip 與 take 用於分頁
+func (c *Client) Search(query string, skip, take int, prerelease bool) (*SearchResult, error) {
+	searchURL, err := c.resource(searchQueryServiceType)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("skip", strconv.Itoa(skip))
+	params.Set("take", strconv.Itoa(take))
+	params.Set("prerelease", strconv.FormatBool(prerelease))
+	params.Set("semVerLevel", "2.0.0")
+
+	var result SearchResult
+	if err := c.getJSON(searchURL+"?"+params.Encode(), &result); err != nil {
+		return nil, fmt.Errorf("failed to search for %q: %v", query, err)
+	}
+	return &result, nil
+}
@@ -1,20 +1,154 @@
 package nuget
 
 import (
-	"path/filepath"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
-// DetermineVersionIfEmpty 若原本版本为空或為latest，從目錄名稱推斷版本
-func DetermineVersionIfEmpty(packageName, packageVersion, packageInstallDir string) (string, error) {
-	if packageVersion == "" || packageVersion == "latest" {
-		baseName := filepath.Base(packageInstallDir)
-		prefix := packageName + "."
-		if strings.HasPrefix(baseName, prefix) {
-			return strings.TrimPrefix(baseName, prefix), nil
-		} else {
-			return "1.0.0", nil
+// Version 為 NuGet 版本（SemVer 2.0 加上第四段 revision）
+type Version struct {
+	Major    int
+	Minor    int
+	Patch    int
+	Revision int
+	Release  []string // prerelease 標籤，以 "." 分隔
+	Metadata string   // build metadata，不參與比較
+}
+
+// ParseVersion 解析 "1.0"、"1.2.3.4"、"2.0.0-beta.1+build" 等 NuGet 版本字串
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return v, fmt.Errorf("empty version")
+	}
+
+	if i := strings.Index(str, "+"); i >= 0 {
+		v.Metadata = str[i+1:]
+		str = str[:i]
+		if v.Metadata == "" {
+			return v, fmt.Errorf("invalid version %q: empty build metadata", s)
+		}
+	}
+	if i := strings.Index(str, "-"); i >= 0 {
+		release := str[i+1:]
+		str = str[:i]
+		if release == "" {
+			return v, fmt.Errorf("invalid version %q: empty prerelease label", s)
+		}
+		v.Release = strings.Split(release, ".")
+		for _, label := range v.Release {
+			if label == "" {
+				return v, fmt.Errorf("invalid version %q: empty prerelease label", s)
+			}
+		}
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) < 1 || len(parts) > 4 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	nums := make([]int, 4)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
 		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch, v.Revision = nums[0], nums[1], nums[2], nums[3]
+	return v, nil
+}
+
+// MustParseVersion 同 ParseVersion，解析失敗時 panic，僅用於常數
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// IsPrerelease 是否為 prerelease 版本
+func (v Version) IsPrerelease() bool {
+	return len(v.Release) > 0
+}
+
+// String 回傳 NuGet 正規化後的版本字串（"1.0" 與 "1.0.0.0" 皆為 "1.0.0"）
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Revision > 0 {
+		s += fmt.Sprintf(".%d", v.Revision)
+	}
+	if len(v.Release) > 0 {
+		s += "-" + strings.Join(v.Release, ".")
+	}
+	return s
+}
+
+// Equal 判斷兩個版本在 NuGet 語意下是否相同（忽略 build metadata）
+func (v Version) Equal(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// Compare 比較兩個版本，回傳 -1、0 或 1
+func (v Version) Compare(other Version) int {
+	if c := compareInt(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Revision, other.Revision); c != 0 {
+		return c
+	}
+	return compareRelease(v.Release, other.Release)
+}
+
+// compareRelease 依 SemVer 2.0 規則比較 prerelease 標籤（NuGet 不分大小寫）
+func compareRelease(a, b []string) int {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	// 穩定版大於任何 prerelease
+	if len(a) == 0 {
+		return 1
+	}
+	if len(b) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(strings.ToLower(a[i]), strings.ToLower(b[i])); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
 	}
-	return packageVersion, nil
+	return 0
 }
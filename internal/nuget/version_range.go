@@ -0,0 +1,259 @@
+package nuget
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FloatBehavior 描述浮動版本（如 "1.*"、"13.0.*"）浮動的位置
+type FloatBehavior int
+
+const (
+	FloatNone FloatBehavior = iota
+	FloatPrerelease
+	FloatRevision
+	FloatPatch
+	FloatMinor
+	FloatMajor
+)
+
+// VersionRange 為 NuGet 版本範圍，支援 interval 語法（"[1.2,2.0)"）、
+// 最低版本（"1.0"）與浮動版本（"1.*"、"13.0.*"、"1.0.0-*"）
+type VersionRange struct {
+	Min          *Version
+	MinInclusive bool
+	Max          *Version
+	MaxInclusive bool
+	Float        FloatBehavior
+	floatRelease string // FloatPrerelease 時 prerelease 標籤須有的前綴（"1.0.0-beta.*" 為 "beta."）
+	original     string
+}
+
+// AllVersions 接受任何版本並選擇最新者，對應 "" 或 "latest"
+var AllVersions = VersionRange{Float: FloatMajor, original: "*"}
+
+// ParseVersionRange 解析 NuGet 版本範圍字串
+func ParseVersionRange(s string) (VersionRange, error) {
+	str := strings.TrimSpace(s)
+	if str == "" || strings.EqualFold(str, "latest") || str == "*" {
+		return AllVersions, nil
+	}
+
+	if strings.Contains(str, "*") {
+		return parseFloatRange(str)
+	}
+
+	if str[0] != '[' && str[0] != '(' {
+		v, err := ParseVersion(str)
+		if err != nil {
+			return VersionRange{}, err
+		}
+		return VersionRange{Min: &v, MinInclusive: true, original: str}, nil
+	}
+
+	last := str[len(str)-1]
+	if len(str) < 3 || (last != ']' && last != ')') {
+		return VersionRange{}, fmt.Errorf("invalid version range %q", s)
+	}
+	r := VersionRange{
+		MinInclusive: str[0] == '[',
+		MaxInclusive: last == ']',
+		original:     str,
+	}
+	body := str[1 : len(str)-1]
+	bounds := strings.Split(body, ",")
+	switch len(bounds) {
+	case 1:
+		// "[1.0]" 為精確版本
+		if !r.MinInclusive || !r.MaxInclusive {
+			return VersionRange{}, fmt.Errorf("invalid version range %q", s)
+		}
+		v, err := ParseVersion(bounds[0])
+		if err != nil {
+			return VersionRange{}, err
+		}
+		r.Min, r.Max = &v, &v
+	case 2:
+		if lower := strings.TrimSpace(bounds[0]); lower != "" {
+			v, err := ParseVersion(lower)
+			if err != nil {
+				return VersionRange{}, err
+			}
+			r.Min = &v
+		}
+		if upper := strings.TrimSpace(bounds[1]); upper != "" {
+			v, err := ParseVersion(upper)
+			if err != nil {
+				return VersionRange{}, err
+			}
+			r.Max = &v
+		}
+		if r.Min == nil && r.Max == nil {
+			return VersionRange{}, fmt.Errorf("invalid version range %q", s)
+		}
+		if r.Min != nil && r.Max != nil && r.Min.Compare(*r.Max) > 0 {
+			return VersionRange{}, fmt.Errorf("invalid version range %q: lower bound is greater than upper bound", s)
+		}
+	default:
+		return VersionRange{}, fmt.Errorf("invalid version range %q", s)
+	}
+	return r, nil
+}
+
+// parseFloatRange 解析 "1.*"、"1.2.*"、"1.2.3.*"、"1.0.0-*"、"1.0.0-beta.*"
+func parseFloatRange(str string) (VersionRange, error) {
+	r := VersionRange{MinInclusive: true, original: str}
+
+	if i := strings.Index(str, "-"); i >= 0 {
+		if !strings.HasSuffix(str, "*") || strings.Count(str, "*") != 1 {
+			return VersionRange{}, fmt.Errorf("invalid floating version %q", str)
+		}
+		// prerelease 浮動以最小的 prerelease 標籤作為下限
+		base := strings.TrimSuffix(str, "*")
+		release := base[i+1:]
+		if release == "" || strings.HasSuffix(release, ".") {
+			base += "0"
+		}
+		v, err := ParseVersion(base)
+		if err != nil {
+			return VersionRange{}, err
+		}
+		r.Min = &v
+		r.Float = FloatPrerelease
+		r.floatRelease = release
+		return r, nil
+	}
+
+	parts := strings.Split(str, ".")
+	if parts[len(parts)-1] != "*" || strings.Count(str, "*") != 1 || len(parts) > 4 {
+		return VersionRange{}, fmt.Errorf("invalid floating version %q", str)
+	}
+	r.Float = []FloatBehavior{FloatMajor, FloatMinor, FloatPatch, FloatRevision}[len(parts)-1]
+	if len(parts) == 1 {
+		return AllVersions, nil
+	}
+	v, err := ParseVersion(strings.Join(parts[:len(parts)-1], "."))
+	if err != nil {
+		return VersionRange{}, err
+	}
+	r.Min = &v
+	return r, nil
+}
+
+// String 回傳原始的範圍字串
+func (r VersionRange) String() string {
+	if r.original != "" {
+		return r.original
+	}
+	return r.PrettyString()
+}
+
+// PrettyString 以 interval 語法描述範圍
+func (r VersionRange) PrettyString() string {
+	if r.Min != nil && r.Max != nil && r.MinInclusive && r.MaxInclusive && r.Min.Equal(*r.Max) {
+		return "[" + r.Min.String() + "]"
+	}
+	var b strings.Builder
+	if r.MinInclusive {
+		b.WriteString("[")
+	} else {
+		b.WriteString("(")
+	}
+	if r.Min != nil {
+		b.WriteString(r.Min.String())
+	}
+	b.WriteString(", ")
+	if r.Max != nil {
+		b.WriteString(r.Max.String())
+	}
+	if r.MaxInclusive {
+		b.WriteString("]")
+	} else {
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// IsFloating 是否為浮動版本
+func (r VersionRange) IsFloating() bool {
+	return r.Float != FloatNone
+}
+
+// AllowsPrerelease 範圍本身是否要求 prerelease（上下限含 prerelease 標籤或 prerelease 浮動）
+func (r VersionRange) AllowsPrerelease() bool {
+	return r.Float == FloatPrerelease ||
+		(r.Min != nil && r.Min.IsPrerelease()) ||
+		(r.Max != nil && r.Max.IsPrerelease())
+}
+
+// Satisfies 判斷版本是否落在範圍內
+func (r VersionRange) Satisfies(v Version) bool {
+	if r.Min != nil {
+		c := v.Compare(*r.Min)
+		if c < 0 || (c == 0 && !r.MinInclusive) {
+			return false
+		}
+	}
+	if r.Max != nil {
+		c := v.Compare(*r.Max)
+		if c > 0 || (c == 0 && !r.MaxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesFloat 判斷版本是否符合浮動前綴（例如 "13.0.*" 需 Major=13、Minor=0）
+func (r VersionRange) matchesFloat(v Version) bool {
+	if r.Min == nil {
+		return true
+	}
+	lower := *r.Min
+	switch r.Float {
+	case FloatPrerelease:
+		// 同版本號的穩定版也符合；prerelease 須以浮動的標籤前綴開頭（不分大小寫）
+		if v.Major != lower.Major || v.Minor != lower.Minor || v.Patch != lower.Patch || v.Revision != lower.Revision {
+			return false
+		}
+		return !v.IsPrerelease() || strings.HasPrefix(strings.ToLower(strings.Join(v.Release, ".")), strings.ToLower(r.floatRelease))
+	case FloatRevision:
+		return v.Major == lower.Major && v.Minor == lower.Minor && v.Patch == lower.Patch
+	case FloatPatch:
+		return v.Major == lower.Major && v.Minor == lower.Minor
+	case FloatMinor:
+		return v.Major == lower.Major
+	}
+	return true
+}
+
+// FindBestMatch 從 feed 的版本清單中選出最佳版本：
+// 浮動版本選擇符合浮動前綴的最高版本，否則依 NuGet 規則選擇滿足範圍的最低版本
+func (r VersionRange) FindBestMatch(versions []Version, allowPrerelease bool) (Version, bool) {
+	allowPrerelease = allowPrerelease || r.AllowsPrerelease()
+
+	var best *Version
+	var bestFloat *Version
+	for i := range versions {
+		v := versions[i]
+		if v.IsPrerelease() && !allowPrerelease {
+			continue
+		}
+		if !r.Satisfies(v) {
+			continue
+		}
+		if best == nil || v.Compare(*best) < 0 {
+			best = &versions[i]
+		}
+		if r.IsFloating() && r.matchesFloat(v) && (bestFloat == nil || v.Compare(*bestFloat) > 0) {
+			bestFloat = &versions[i]
+		}
+	}
+
+	if bestFloat != nil {
+		return *bestFloat, true
+	}
+	if best != nil {
+		return *best, true
+	}
+	return Version{}, false
+}
@@ -0,0 +1,143 @@
+package nuget
+
+import "testing"
+
+func TestParseVersionRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		pretty  string
+		float   FloatBehavior
+		invalid bool
+	}{
+		{in: "", pretty: "(, )", float: FloatMajor},
+		{in: "latest", pretty: "(, )", float: FloatMajor},
+		{in: "*", pretty: "(, )", float: FloatMajor},
+		{in: "1.0", pretty: "[1.0.0, )"},
+		{in: "[1.0]", pretty: "[1.0.0]"},
+		{in: "[1.0,2.0)", pretty: "[1.0.0, 2.0.0)"},
+		{in: "(1.0, 2.0]", pretty: "(1.0.0, 2.0.0]"},
+		{in: "(,2.0)", pretty: "(, 2.0.0)"},
+		{in: "(1.0,)", pretty: "(1.0.0, )"},
+		{in: "1.*", pretty: "[1.0.0, )", float: FloatMinor},
+		{in: "1.2.*", pretty: "[1.2.0, )", float: FloatPatch},
+		{in: "1.2.3.*", pretty: "[1.2.3, )", float: FloatRevision},
+		{in: "1.0.0-*", pretty: "[1.0.0-0, )", float: FloatPrerelease},
+		{in: "1.0.0-beta.*", pretty: "[1.0.0-beta.0, )", float: FloatPrerelease},
+		{in: "1.0.0-beta*", pretty: "[1.0.0-beta, )", float: FloatPrerelease},
+		{in: "(1.0]", invalid: true},
+		{in: "[2.0,1.0]", invalid: true},
+		{in: "[,]", invalid: true},
+		{in: "[1.0,2.0,3.0]", invalid: true},
+		{in: "[1.0", invalid: true},
+		{in: "1.*.3", invalid: true},
+		{in: "1.0.0-*.beta", invalid: true},
+		{in: "1.2.3.4.*", invalid: true},
+		{in: "abc", invalid: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersionRange(tt.in)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("ParseVersionRange(%q) = %s, want an error", tt.in, got.PrettyString())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersionRange(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got.PrettyString() != tt.pretty || got.Float != tt.float {
+			t.Errorf("ParseVersionRange(%q) = %s (float %d), want %s (float %d)", tt.in, got.PrettyString(), got.Float, tt.pretty, tt.float)
+		}
+	}
+}
+
+func TestFindBestMatch(t *testing.T) {
+	var versions []Version
+	for _, v := range []string{
+		"0.9.0", "1.0.0-alpha.1", "1.0.0-beta.1", "1.0.0-beta.2", "1.0.0-rc.1", "1.0.0", "1.0.1",
+		"1.1.0", "1.2.0-beta", "1.2.0", "1.2.5", "2.0.0-preview.1", "2.0.0", "2.1.0.3",
+	} {
+		versions = append(versions, MustParseVersion(v))
+	}
+
+	tests := []struct {
+		r          string
+		prerelease bool
+		want       string // 空字串代表沒有符合的版本
+	}{
+		// 非浮動範圍選擇最低的符合版本
+		{r: "1.0", want: "1.0.0"},
+		{r: "[1.0.1,2.0)", want: "1.0.1"},
+		{r: "(1.0.0,2.0)", want: "1.0.1"},
+		{r: "(1.0.1,1.1.0)", want: ""},
+		{r: "(1.0.1,1.1.0]", want: "1.1.0"},
+		{r: "(,1.0)", want: "0.9.0"},
+		{r: "[1.2.0]", want: "1.2.0"},
+		{r: "[3.0,)", want: ""},
+		// 浮動版本選擇符合前綴的最高版本
+		{r: "*", want: "2.1.0.3"},
+		{r: "1.*", want: "1.2.5"},
+		{r: "1.0.*", want: "1.0.1"},
+		{r: "2.1.0.*", want: "2.1.0.3"},
+		// 沒有符合浮動前綴的版本時退回最低的符合版本
+		{r: "1.3.*", want: "2.0.0"},
+		// prerelease 只在範圍要求或允許時選擇
+		{r: "[1.0.0-alpha.1,1.0.0)", want: "1.0.0-alpha.1"},
+		{r: "2.0.0-preview.1", want: "2.0.0-preview.1"},
+		{r: "(1.1.0,1.2.0)", want: ""},
+		{r: "(1.1.0,1.2.0)", prerelease: true, want: "1.2.0-beta"},
+		{r: "*", prerelease: true, want: "2.1.0.3"},
+		// prerelease 浮動：同版本號的穩定版也符合，prerelease 須符合標籤前綴
+		{r: "1.0.0-*", want: "1.0.0"},
+		{r: "1.0.0-beta.*", want: "1.0.0"},
+		{r: "1.2.0-*", want: "1.2.0"},
+		{r: "2.0.0-preview.*", want: "2.0.0"},
+	}
+	for _, tt := range tests {
+		r, err := ParseVersionRange(tt.r)
+		if err != nil {
+			t.Fatalf("ParseVersionRange(%q) error = %v", tt.r, err)
+		}
+		got, ok := r.FindBestMatch(versions, tt.prerelease)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s (prerelease %v) matched %s, want no match", tt.r, tt.prerelease, got)
+			}
+			continue
+		}
+		if !ok || got.String() != tt.want {
+			t.Errorf("%s (prerelease %v) = %s (%v), want %s", tt.r, tt.prerelease, got, ok, tt.want)
+		}
+	}
+}
+
+func TestFindBestMatchFloatingReleaseLabel(t *testing.T) {
+	// 只有 prerelease 時，標籤前綴決定選擇的版本
+	var versions []Version
+	for _, v := range []string{"1.0.0-alpha.3", "1.0.0-beta.1", "1.0.0-beta.2", "1.0.0-Beta.3x", "1.0.0-rc.1", "1.0.1-beta.9"} {
+		versions = append(versions, MustParseVersion(v))
+	}
+	tests := []struct {
+		r    string
+		want string
+	}{
+		{"1.0.0-*", "1.0.0-rc.1"},
+		{"1.0.0-beta.*", "1.0.0-Beta.3x"},
+		{"1.0.0-alpha.*", "1.0.0-alpha.3"},
+		{"1.0.0-rc*", "1.0.0-rc.1"},
+		// 沒有符合前綴的 prerelease 時退回最低的符合版本
+		{"1.0.0-gamma.*", "1.0.0-rc.1"},
+		{"1.0.1-beta.*", "1.0.1-beta.9"},
+	}
+	for _, tt := range tests {
+		r, err := ParseVersionRange(tt.r)
+		if err != nil {
+			t.Fatalf("ParseVersionRange(%q) error = %v", tt.r, err)
+		}
+		got, ok := r.FindBestMatch(versions, false)
+		if !ok || got.String() != tt.want {
+			t.Errorf("%s = %s (%v), want %s", tt.r, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package nuget
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		str     string
+		invalid bool
+	}{
+		{in: "1", want: Version{Major: 1}, str: "1.0.0"},
+		{in: "1.0", want: Version{Major: 1}, str: "1.0.0"},
+		{in: " 1.2.3 ", want: Version{Major: 1, Minor: 2, Patch: 3}, str: "1.2.3"},
+		{in: "1.2.3.4", want: Version{Major: 1, Minor: 2, Patch: 3, Revision: 4}, str: "1.2.3.4"},
+		{in: "1.0.0.0", want: Version{Major: 1}, str: "1.0.0"},
+		{in: "2.0.0-beta.1", want: Version{Major: 2, Release: []string{"beta", "1"}}, str: "2.0.0-beta.1"},
+		{in: "2.0.0-rc.1+build.5", want: Version{Major: 2, Release: []string{"rc", "1"}, Metadata: "build.5"}, str: "2.0.0-rc.1"},
+		{in: "1.0.0+sha", want: Version{Major: 1, Metadata: "sha"}, str: "1.0.0"},
+		{in: "", invalid: true},
+		{in: "1.2.3.4.5", invalid: true},
+		{in: "1.x", invalid: true},
+		{in: "-1.0", invalid: true},
+		{in: "1.0-", invalid: true},
+		{in: "1.0-beta..1", invalid: true},
+		{in: "1.0+", invalid: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("ParseVersion(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVersion(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseVersion(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0.0.0", 0},
+		{"1.0.0+a", "1.0.0+b", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0.1", "1.0.0", 1},
+		{"1.0.0.1", "1.0.1", -1},
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-BETA", "1.0.0-beta", 0},
+		{"1.0.0-beta.2", "1.0.0-beta.10", -1},
+		{"1.0.0-beta", "1.0.0-beta.1", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+	}
+	for _, tt := range tests {
+		a, b := MustParseVersion(tt.a), MustParseVersion(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Compare(a); got != -tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
@@ -1,24 +1,178 @@
 package packagemanifest
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-func CreateAsmdef(asmName, dllName, outputPath string) error {
-	asmdefName := strings.ToLower(strings.ReplaceAll(asmName, ".", "-")) + "-asmdef"
-	asmdefContent := fmt.Sprintf(`{
-  "name": "%s",
-  "overrideReferences": true,
-  "precompiledReferences": [
-    "%s"
-  ],
-  "autoReferenced": true,
-  "noEngineReferences": false
-}`, asmdefName, dllName)
-
-	asmdefPath := filepath.Join(outputPath, asmdefName+".asmdef")
-	return os.WriteFile(asmdefPath, []byte(asmdefContent), 0644)
+// Asmdef 為 Unity 的 assembly definition（.asmdef），欄位順序與 Unity 寫出的一致
+type Asmdef struct {
+	Name                  string          `json:"name"`
+	References            []string        `json:"references"`
+	IncludePlatforms      []string        `json:"includePlatforms"`
+	ExcludePlatforms      []string        `json:"excludePlatforms"`
+	AllowUnsafeCode       bool            `json:"allowUnsafeCode"`
+	OverrideReferences    bool            `json:"overrideReferences"`
+	PrecompiledReferences []string        `json:"precompiledReferences"`
+	AutoReferenced        bool            `json:"autoReferenced"`
+	DefineConstraints     []string        `json:"defineConstraints"`
+	VersionDefines        []VersionDefine `json:"versionDefines"`
+	NoEngineReferences    bool            `json:"noEngineReferences"`
+}
+
+// VersionDefine 在專案中的套件（或 "Unity"）版本符合 Expression 時定義 Define
+type VersionDefine struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Define     string `json:"define"`
+}
+
+// ParseVersionDefine 解析 "名稱:版本範圍:符號"，例如 "com.unity.inputsystem:[1.0,2.0):HAS_INPUT_SYSTEM"；
+// 版本範圍可為空字串（任何版本）
+func ParseVersionDefine(s string) (VersionDefine, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[2]) == "" {
+		return VersionDefine{}, fmt.Errorf("invalid version define %q: use <package>:<expression>:<define>", s)
+	}
+	return VersionDefine{Name: strings.TrimSpace(parts[0]), Expression: strings.TrimSpace(parts[1]), Define: strings.TrimSpace(parts[2])}, nil
+}
+
+// ParseVersionDefines 解析以 ";" 分隔的 version define 清單（版本範圍中可能有 ","）
+func ParseVersionDefines(s string) ([]VersionDefine, error) {
+	var defines []VersionDefine
+	for _, item := range strings.Split(s, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		define, err := ParseVersionDefine(item)
+		if err != nil {
+			return nil, err
+		}
+		defines = append(defines, define)
+	}
+	return defines, nil
+}
+
+// AsmdefSettings 為匯出的 asmdef 可設定的欄位
+type AsmdefSettings struct {
+	IncludePlatforms   []string // 空代表所有平台
+	ExcludePlatforms   []string
+	DefineConstraints  []string
+	VersionDefines     []VersionDefine
+	AllowUnsafeCode    bool
+	NoEngineReferences bool
+}
+
+// asmdefPlatforms 為 asmdef 的 includePlatforms / excludePlatforms 使用的平台名稱
+var asmdefPlatforms = []string{
+	"Android",
+	"CloudRendering",
+	"Editor",
+	"EmbeddedLinux",
+	"GameCoreScarlett",
+	"GameCoreXboxOne",
+	"iOS",
+	"LinuxStandalone64",
+	"Lumin",
+	"macOSStandalone",
+	"PS4",
+	"PS5",
+	"QNX",
+	"Stadia",
+	"Switch",
+	"tvOS",
+	"VisionOS",
+	"WSA",
+	"WebGL",
+	"WindowsStandalone32",
+	"WindowsStandalone64",
+	"XboxOne",
+}
+
+// ParseAsmdefPlatform 解析 asmdef 的平台名稱（不分大小寫），並接受與 PluginImporter build target 相同的別名
+func ParseAsmdefPlatform(name string) (string, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, platform := range asmdefPlatforms {
+		if lower == strings.ToLower(platform) {
+			return platform, nil
+		}
+	}
+	switch lower {
+	case "windows", "win64", "win-x64":
+		return "WindowsStandalone64", nil
+	case "win", "win32", "win-x86":
+		return "WindowsStandalone32", nil
+	case "osx", "macos", "mac", "osxuniversal":
+		return "macOSStandalone", nil
+	case "linux", "linux64":
+		return "LinuxStandalone64", nil
+	case "iphone":
+		return "iOS", nil
+	case "uwp", "windowsstoreapps":
+		return "WSA", nil
+	}
+	return "", fmt.Errorf("unknown asmdef platform %q", name)
+}
+
+// NewAsmdefSettings 檢查平台名稱並建立 AsmdefSettings；Unity 不允許同時指定 include 與 exclude
+func NewAsmdefSettings(include, exclude []string) (AsmdefSettings, error) {
+	var settings AsmdefSettings
+	if len(include) > 0 && len(exclude) > 0 {
+		return settings, fmt.Errorf("an asmdef cannot both include and exclude platforms")
+	}
+	for _, name := range include {
+		platform, err := ParseAsmdefPlatform(name)
+		if err != nil {
+			return settings, err
+		}
+		settings.IncludePlatforms = append(settings.IncludePlatforms, platform)
+	}
+	for _, name := range exclude {
+		platform, err := ParseAsmdefPlatform(name)
+		if err != nil {
+			return settings, err
+		}
+		settings.ExcludePlatforms = append(settings.ExcludePlatforms, platform)
+	}
+	return settings, nil
+}
+
+// AsmdefName 回傳以組件 asmName 命名的 asmdef 名稱（小寫，"." 換成 "-"，加上 "-asmdef"）
+func AsmdefName(asmName string) string {
+	return strings.ToLower(strings.ReplaceAll(asmName, ".", "-")) + "-asmdef"
+}
+
+// NewAsmdef 建立以 asmName 命名、參照 dllNames 中所有 DLL 的 asmdef
+func NewAsmdef(asmName string, dllNames []string, settings AsmdefSettings) Asmdef {
+	return Asmdef{
+		Name:                  AsmdefName(asmName),
+		References:            []string{},
+		IncludePlatforms:      nonNil(settings.IncludePlatforms),
+		ExcludePlatforms:      nonNil(settings.ExcludePlatforms),
+		AllowUnsafeCode:       settings.AllowUnsafeCode,
+		OverrideReferences:    true,
+		PrecompiledReferences: nonNil(dllNames),
+		AutoReferenced:        true,
+		DefineConstraints:     nonNil(settings.DefineConstraints),
+		VersionDefines:        append([]VersionDefine{}, settings.VersionDefines...),
+		NoEngineReferences:    settings.NoEngineReferences,
+	}
+}
+
+// CreateAsmdef 將 asmdef 寫入 outputPath/<Name>.asmdef，回傳檔名
+func CreateAsmdef(asmdef Asmdef, outputPath string) (string, error) {
+	content, err := json.MarshalIndent(asmdef, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	fileName := asmdef.Name + ".asmdef"
+	return fileName, os.WriteFile(filepath.Join(outputPath, fileName), content, 0644)
+}
+
+// nonNil 讓空清單輸出為 [] 而不是 null
+func nonNil(items []string) []string {
+	return append([]string{}, items...)
 }
@@ -0,0 +1,179 @@
+package packagemanifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionDefine(t *testing.T) {
+	tests := []struct {
+		input string
+		want  VersionDefine
+		err   bool
+	}{
+		{"com.unity.inputsystem:[1.0,2.0):HAS_INPUT_SYSTEM", VersionDefine{"com.unity.inputsystem", "[1.0,2.0)", "HAS_INPUT_SYSTEM"}, false},
+		{" Unity : 2021.3 : UNITY_2021_3_OR_NEWER ", VersionDefine{"Unity", "2021.3", "UNITY_2021_3_OR_NEWER"}, false},
+		{"com.unity.burst::HAS_BURST", VersionDefine{"com.unity.burst", "", "HAS_BURST"}, false},
+		{"com.unity.burst:1.0", VersionDefine{}, true},
+		{":1.0:HAS_BURST", VersionDefine{}, true},
+		{"com.unity.burst:1.0: ", VersionDefine{}, true},
+		{"a:b:c:d", VersionDefine{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersionDefine(tt.input)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseVersionDefine(%q) = %+v, %v; want %+v, error %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+
+	defines, err := ParseVersionDefines("com.unity.burst::HAS_BURST; ;Unity:[2021.3,2022.1):UNITY_2021")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VersionDefine{{"com.unity.burst", "", "HAS_BURST"}, {"Unity", "[2021.3,2022.1)", "UNITY_2021"}}
+	if !reflect.DeepEqual(defines, want) {
+		t.Errorf("ParseVersionDefines() = %+v, want %+v", defines, want)
+	}
+	if _, err := ParseVersionDefines("com.unity.burst::HAS_BURST;broken"); err == nil {
+		t.Error("ParseVersionDefines accepted an invalid item")
+	}
+}
+
+func TestParseAsmdefPlatform(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"Android", "Android"},
+		{" webgl ", "WebGL"},
+		{"IOS", "iOS"},
+		{"win64", "WindowsStandalone64"},
+		{"win-x86", "WindowsStandalone32"},
+		{"osx", "macOSStandalone"},
+		{"linux", "LinuxStandalone64"},
+		{"iPhone", "iOS"},
+		{"uwp", "WSA"},
+	}
+	for _, tt := range tests {
+		if got, err := ParseAsmdefPlatform(tt.input); err != nil || got != tt.want {
+			t.Errorf("ParseAsmdefPlatform(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+	for _, input := range []string{"", "Windows Standalone", "PlayStation"} {
+		if got, err := ParseAsmdefPlatform(input); err == nil {
+			t.Errorf("ParseAsmdefPlatform(%q) = %q, want an error", input, got)
+		}
+	}
+}
+
+func TestNewAsmdefSettings(t *testing.T) {
+	settings, err := NewAsmdefSettings([]string{"windows", "osx", "Editor"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"WindowsStandalone64", "macOSStandalone", "Editor"}; !reflect.DeepEqual(settings.IncludePlatforms, want) || settings.ExcludePlatforms != nil {
+		t.Errorf("include settings = %+v, want include %v", settings, want)
+	}
+
+	settings, err = NewAsmdefSettings(nil, []string{"webgl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"WebGL"}; !reflect.DeepEqual(settings.ExcludePlatforms, want) || settings.IncludePlatforms != nil {
+		t.Errorf("exclude settings = %+v, want exclude %v", settings, want)
+	}
+
+	// Unity 不允許同時指定 include 與 exclude
+	if _, err := NewAsmdefSettings([]string{"Android"}, []string{"WebGL"}); err == nil || !strings.Contains(err.Error(), "both include and exclude") {
+		t.Errorf("NewAsmdefSettings(include, exclude) error = %v", err)
+	}
+	if _, err := NewAsmdefSettings(nil, []string{"Dreamcast"}); err == nil {
+		t.Error("NewAsmdefSettings accepted an unknown platform")
+	}
+}
+
+func TestCreateAsmdefEmptyLists(t *testing.T) {
+	// 空清單必須輸出為 []，Unity 不接受 null
+	dir := t.TempDir()
+	fileName, err := CreateAsmdef(NewAsmdef("Foo", nil, AsmdefSettings{}), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "null") {
+		t.Errorf("asmdef contains null:\n%s", data)
+	}
+	for _, field := range []string{"references", "includePlatforms", "excludePlatforms", "precompiledReferences", "defineConstraints", "versionDefines"} {
+		if !strings.Contains(string(data), `"`+field+`": []`) {
+			t.Errorf("%s is not an empty list:\n%s", field, data)
+		}
+	}
+}
+
+func TestCreateAsmdefGolden(t *testing.T) {
+	settings, err := NewAsmdefSettings(nil, []string{"webgl", "ios"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.DefineConstraints = []string{"!DISABLE_FOO"}
+	settings.VersionDefines = []VersionDefine{
+		{Name: "com.unity.inputsystem", Expression: "[1.0,2.0)", Define: "HAS_INPUT_SYSTEM"},
+		{Name: "Unity", Expression: "2021.3", Define: "FOO_2021_3"},
+	}
+	settings.AllowUnsafeCode = true
+	asmdef := NewAsmdef("Foo.Bar", []string{"Foo.Bar.dll", `Foo "Quoted".dll`, `Back\slash.dll`}, settings)
+
+	dir := t.TempDir()
+	fileName, err := CreateAsmdef(asmdef, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileName != "foo-bar-asmdef.asmdef" {
+		t.Errorf("CreateAsmdef() = %q, want foo-bar-asmdef.asmdef", fileName)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "name": "foo-bar-asmdef",
+  "references": [],
+  "includePlatforms": [],
+  "excludePlatforms": [
+    "WebGL",
+    "iOS"
+  ],
+  "allowUnsafeCode": true,
+  "overrideReferences": true,
+  "precompiledReferences": [
+    "Foo.Bar.dll",
+    "Foo \"Quoted\".dll",
+    "Back\\slash.dll"
+  ],
+  "autoReferenced": true,
+  "defineConstraints": [
+    "!DISABLE_FOO"
+  ],
+  "versionDefines": [
+    {
+      "name": "com.unity.inputsystem",
+      "expression": "[1.0,2.0)",
+      "define": "HAS_INPUT_SYSTEM"
+    },
+    {
+      "name": "Unity",
+      "expression": "2021.3",
+      "define": "FOO_2021_3"
+    }
+  ],
+  "noEngineReferences": false
+}`
+	if string(data) != want {
+		t.Errorf("asmdef =\n%s\nwant\n%s", data, want)
+	}
+}
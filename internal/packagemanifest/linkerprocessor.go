@@ -0,0 +1,60 @@
+package packagemanifest
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LinkerProcessorDir 為 CreateLinkerProcessor 建立的 Editor 資料夾，位於套件根目錄下
+const LinkerProcessorDir = "Editor"
+
+// linkerProcessorScript 在建置時將套件的 Runtime/link.xml 加入 UnityLinker 的輸入：
+// UnityLinker 只讀取 Assets/ 下的 link.xml，UPM 套件中的 link.xml 會被忽略。
+// OnBeforeRun 與 OnAfterRun 為 Unity 2021.2 之前的介面成員，之後的版本中只是未使用的方法
+const linkerProcessorScript = `using System.IO;
+using UnityEditor.Build;
+using UnityEditor.Build.Reporting;
+using UnityEditor.UnityLinker;
+
+// Generated by nuget-2-dll-go. UnityLinker ignores link.xml files inside packages,
+// so this adds the package's Runtime/link.xml to the linker input at build time.
+class LinkXmlProcessor : IUnityLinkerProcessor
+{
+    public int callbackOrder => 0;
+
+    public string GenerateAdditionalLinkXmlFile(BuildReport report, UnityLinkerBuildPipelineData data)
+    {
+        var package = UnityEditor.PackageManager.PackageInfo.FindForAssembly(typeof(LinkXmlProcessor).Assembly);
+        if (package == null)
+            return null; // Under Assets/ the link.xml is already used.
+        var path = Path.GetFullPath(Path.Combine(package.resolvedPath, "Runtime", "link.xml"));
+        return File.Exists(path) ? path : null;
+    }
+
+    public void OnBeforeRun(BuildReport report, UnityLinkerBuildPipelineData data) { }
+
+    public void OnAfterRun(BuildReport report, UnityLinkerBuildPipelineData data) { }
+}
+`
+
+// CreateLinkerProcessor 在 packagePath/Editor 建立只在 Editor 編譯的 asmdef（<upmName>.editor）
+// 與 IUnityLinkerProcessor，讓 UPM 套件的 link.xml 在建置時生效
+func CreateLinkerProcessor(upmName, packagePath string) error {
+	dir := filepath.Join(packagePath, LinkerProcessorDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	asmdef := Asmdef{
+		Name:                  upmName + ".editor",
+		References:            []string{},
+		IncludePlatforms:      []string{"Editor"},
+		ExcludePlatforms:      []string{},
+		PrecompiledReferences: []string{},
+		DefineConstraints:     []string{},
+		VersionDefines:        []VersionDefine{},
+	}
+	if _, err := CreateAsmdef(asmdef, dir); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "LinkXmlProcessor.cs"), []byte(linkerProcessorScript), 0644)
+}
@@ -0,0 +1,40 @@
+package packagemanifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateLinkerProcessor(t *testing.T) {
+	dir := t.TempDir()
+	if err := CreateLinkerProcessor("com.nuget.newtonsoft-json", dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Editor", "com.nuget.newtonsoft-json.editor.asmdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var asmdef Asmdef
+	if err := json.Unmarshal(data, &asmdef); err != nil {
+		t.Fatal(err)
+	}
+	// 只在 Editor 編譯，且不被其他組件自動參照
+	if !reflect.DeepEqual(asmdef.IncludePlatforms, []string{"Editor"}) || asmdef.AutoReferenced {
+		t.Errorf("asmdef = %+v, want an Editor-only assembly that is not auto referenced", asmdef)
+	}
+
+	script, err := os.ReadFile(filepath.Join(dir, "Editor", "LinkXmlProcessor.cs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{": IUnityLinkerProcessor", "GenerateAdditionalLinkXmlFile", `"Runtime", "` + LinkXMLName + `"`} {
+		if !strings.Contains(string(script), want) {
+			t.Errorf("LinkXmlProcessor.cs does not contain %q", want)
+		}
+	}
+}
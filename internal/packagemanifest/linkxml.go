@@ -0,0 +1,69 @@
+package packagemanifest
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// LinkXMLName 為 CreateLinkXML 產生的檔名
+const LinkXMLName = "link.xml"
+
+type linker struct {
+	XMLName    xml.Name         `xml:"linker"`
+	Assemblies []linkerAssembly `xml:"assembly"`
+}
+
+type linkerAssembly struct {
+	FullName   string            `xml:"fullname,attr"`
+	Preserve   string            `xml:"preserve,attr,omitempty"`
+	Namespaces []linkerNamespace `xml:"namespace"`
+}
+
+type linkerNamespace struct {
+	FullName string `xml:"fullname,attr"`
+	Preserve string `xml:"preserve,attr"`
+}
+
+// CreateLinkXML 在 outputPath 建立保留 preserves 的 link.xml；同一組件同時保留整個組件與命名空間時只保留整個組件
+func CreateLinkXML(preserves []unity.Preserve, outputPath string) error {
+	var doc linker
+	index := map[string]int{}
+	for _, p := range preserves {
+		key := strings.ToLower(p.Assembly)
+		i, ok := index[key]
+		if !ok {
+			i = len(doc.Assemblies)
+			index[key] = i
+			doc.Assemblies = append(doc.Assemblies, linkerAssembly{FullName: p.Assembly})
+		}
+		assembly := &doc.Assemblies[i]
+		switch {
+		case assembly.Preserve == "all":
+		case p.Namespace == "":
+			assembly.Preserve = "all"
+			assembly.Namespaces = nil
+		case !hasNamespace(assembly.Namespaces, p.Namespace):
+			assembly.Namespaces = append(assembly.Namespaces, linkerNamespace{FullName: p.Namespace, Preserve: "all"})
+		}
+	}
+
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	return os.WriteFile(filepath.Join(outputPath, LinkXMLName), content, 0644)
+}
+
+func hasNamespace(namespaces []linkerNamespace, name string) bool {
+	for _, ns := range namespaces {
+		if ns.FullName == name {
+			return true
+		}
+	}
+	return false
+}
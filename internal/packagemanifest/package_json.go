@@ -1,23 +1,127 @@
 package packagemanifest
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
 )
 
-func CreatePackageJson(packageName, version, outputPath string) error {
-	normalizedName := "com.nuget." + strings.ToLower(strings.ReplaceAll(packageName, ".", "-"))
-	packageJsonContent := fmt.Sprintf(`{
-  "name": "%s",
-  "displayName": "%s",
-  "version": "%s",
-  "unity": "2019.1",
-  "description": "Auto-generated package for %s",
-  "dependencies": {}
-}`, normalizedName, packageName, version, packageName)
+// PackageName 回傳 NuGet 套件對應的 UPM 套件名稱（com.nuget.<小寫 id，"." 換成 "-">）
+func PackageName(nugetPackageName string) string {
+	return "com.nuget." + strings.ToLower(strings.ReplaceAll(nugetPackageName, ".", "-"))
+}
+
+// ErrRevisionVersion 表示 NuGet 版本有第四段 revision（如 1.2.3.4）。SemVer 在 1.2.3 與 1.2.4 之間
+// 只有 1.2.4 的 prerelease，build metadata 又不影響排序，因此 UPM 版本無法區分並正確排序這些版本
+var ErrRevisionVersion = errors.New("UPM versions cannot represent a fourth (revision) version component")
+
+// UPMVersion 將 NuGet 版本轉為 UPM 接受的 SemVer（去除 build metadata）；有 revision 的版本回傳 ErrRevisionVersion
+func UPMVersion(version string) (string, error) {
+	v, err := nuget.ParseVersion(version)
+	if err != nil {
+		return "", err
+	}
+	if v.Revision > 0 {
+		return "", fmt.Errorf("%s: %w", version, ErrRevisionVersion)
+	}
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.IsPrerelease() {
+		s += "-" + strings.Join(v.Release, ".")
+	}
+	return s, nil
+}
+
+// packageJSON 為 UPM 套件的 package.json
+type packageJSON struct {
+	Name         string            `json:"name"`
+	DisplayName  string            `json:"displayName"`
+	Version      string            `json:"version"`
+	Unity        string            `json:"unity"`
+	Description  string            `json:"description"`
+	Dependencies map[string]string `json:"dependencies"`
+}
 
+// CreatePackageJson 在 outputPath 建立 package.json；unityVersion 為套件要求的最低 Unity 版本（匯出的目標），
+// dependencies 為 UPM 套件名稱與版本，可為 nil。version 無法轉為 UPM 版本時回傳 UPMVersion 的錯誤
+func CreatePackageJson(packageName, version string, unityVersion unity.Version, dependencies map[string]string, outputPath string) error {
+	upmVersion, err := UPMVersion(version)
+	if err != nil {
+		return err
+	}
+	if dependencies == nil {
+		dependencies = map[string]string{}
+	}
+	content, err := json.MarshalIndent(packageJSON{
+		Name:         PackageName(packageName),
+		DisplayName:  packageName,
+		Version:      upmVersion,
+		Unity:        unityVersion.String(),
+		Description:  "Auto-generated package for " + packageName,
+		Dependencies: dependencies,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
 	packageJsonPath := filepath.Join(outputPath, "package.json")
-	return os.WriteFile(packageJsonPath, []byte(packageJsonContent), 0644)
+	return os.WriteFile(packageJsonPath, content, 0644)
+}
+
+// NuGetVersion 將 UPMVersion 產生的版本轉回 NuGet 版本；UPMVersion 不產生 build metadata 與 revision，
+// 因此有這兩者的版本不是 UPMVersion 的結果而回傳錯誤
+func NuGetVersion(upmVersion string) (string, error) {
+	v, err := nuget.ParseVersion(upmVersion)
+	if err != nil {
+		return "", err
+	}
+	if v.Metadata != "" || v.Revision > 0 {
+		return "", fmt.Errorf("invalid UPM version %q", upmVersion)
+	}
+	return v.String(), nil
+}
+
+// maxMixedSeparators 為 PackageIDCandidates 嘗試 "." 與 "-" 各種組合的分隔符號數上限，
+// 超過時只嘗試全部為 "." 與全部為 "-"
+const maxMixedSeparators = 4
+
+// PackageIDCandidates 回傳 UPM 套件名稱可能對應的 NuGet 套件 id。
+// PackageName 把 "." 與 "-" 都換成 "-"，因此名稱中的每個 "-" 都可能是兩者之一；
+// NuGet id 多以 "." 分隔，候選依 "-" 的數量由少到多排列。不是 com.nuget. 開頭的名稱回傳 nil
+func PackageIDCandidates(upmName string) []string {
+	rest := strings.TrimPrefix(upmName, "com.nuget.")
+	if rest == upmName || rest == "" {
+		return nil
+	}
+	parts := strings.Split(rest, "-")
+	separators := len(parts) - 1
+	if separators > maxMixedSeparators {
+		return []string{strings.Join(parts, "."), rest}
+	}
+	var candidates []string
+	for dashes := 0; dashes <= separators; dashes++ {
+		for mask := 0; mask < 1<<separators; mask++ {
+			if bits.OnesCount(uint(mask)) != dashes {
+				continue
+			}
+			// mask 的第 i 位元為倒數第 i+1 個分隔符號，後面的 "-" 先嘗試（如 Foo.Bar-Baz）
+			var id strings.Builder
+			id.WriteString(parts[0])
+			for i, part := range parts[1:] {
+				if mask&(1<<(separators-1-i)) != 0 {
+					id.WriteByte('-')
+				} else {
+					id.WriteByte('.')
+				}
+				id.WriteString(part)
+			}
+			candidates = append(candidates, id.String())
+		}
+	}
+	return candidates
 }
@@ -0,0 +1,123 @@
+package packagemanifest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+func TestUPMVersion(t *testing.T) {
+	tests := []struct {
+		nuget    string
+		upm      string
+		revision bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"1.2", "1.2.0", false},
+		{"1.2.3.0", "1.2.3", false},
+		{"2.0.0-beta.1", "2.0.0-beta.1", false},
+		{"1.0.0+sha.abc", "1.0.0", false},
+		{"1.2.3.4", "", true},
+		{"1.2.3.4-beta", "", true},
+	}
+	for _, tt := range tests {
+		got, err := UPMVersion(tt.nuget)
+		if tt.revision {
+			if !errors.Is(err, ErrRevisionVersion) {
+				t.Errorf("UPMVersion(%q) error = %v, want ErrRevisionVersion", tt.nuget, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.upm {
+			t.Errorf("UPMVersion(%q) = %q, %v; want %q", tt.nuget, got, err, tt.upm)
+			continue
+		}
+		back, err := NuGetVersion(got)
+		if err != nil {
+			t.Errorf("NuGetVersion(%q) error = %v", got, err)
+		} else if back != got {
+			t.Errorf("NuGetVersion(%q) = %q, want %q", got, back, got)
+		}
+	}
+	if _, err := UPMVersion("not a version"); err == nil {
+		t.Error("UPMVersion accepted an invalid version")
+	}
+}
+
+func TestNuGetVersionRejectsMetadata(t *testing.T) {
+	// UPMVersion 不產生 build metadata，1.2.3+4 不會對應到 1.2.3.4
+	for _, v := range []string{"1.2.3+4", "1.2.3.4", "x"} {
+		if got, err := NuGetVersion(v); err == nil {
+			t.Errorf("NuGetVersion(%q) = %q, want an error", v, got)
+		}
+	}
+}
+
+func TestCreatePackageJson(t *testing.T) {
+	dir := t.TempDir()
+	deps := map[string]string{"com.nuget.system-memory": "4.5.5"}
+	if err := CreatePackageJson("Foo.Bar", "1.2.3", unity.Version{Major: 2022, Minor: 3}, deps, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got packageJSON
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := packageJSON{
+		Name:         "com.nuget.foo-bar",
+		DisplayName:  "Foo.Bar",
+		Version:      "1.2.3",
+		Unity:        "2022.3", // 匯出的目標版本，而不是固定值
+		Description:  "Auto-generated package for Foo.Bar",
+		Dependencies: deps,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("package.json = %+v, want %+v", got, want)
+	}
+}
+
+func TestPackageIDCandidates(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"com.nuget.newtonsoft", []string{"newtonsoft"}},
+		{"com.nuget.newtonsoft-json", []string{"newtonsoft.json", "newtonsoft-json"}},
+		{"com.nuget.foo-bar-baz", []string{"foo.bar.baz", "foo.bar-baz", "foo-bar.baz", "foo-bar-baz"}},
+		// 超過上限時只嘗試全部為 "." 與全部為 "-"
+		{"com.nuget.a-b-c-d-e-f", []string{"a.b.c.d.e.f", "a-b-c-d-e-f"}},
+		{"com.nuget.", nil},
+		{"com.unity.inputsystem", nil},
+	}
+	for _, tt := range tests {
+		if got := PackageIDCandidates(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PackageIDCandidates(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 每個不超過上限的 id 都能由 PackageName 的結果找回
+	for _, id := range []string{"Foo.Bar-Baz", "Foo-Bar.Baz", "A.B-C.D-E"} {
+		found := false
+		for _, candidate := range PackageIDCandidates(PackageName(id)) {
+			if strings.EqualFold(candidate, id) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("PackageIDCandidates(PackageName(%q)) does not include it", id)
+		}
+	}
+	if got := len(PackageIDCandidates("com.nuget.a-b-c-d-e")); got != 1<<maxMixedSeparators {
+		t.Errorf("%d candidates for %d separators, want %d", got, maxMixedSeparators, 1<<maxMixedSeparators)
+	}
+}
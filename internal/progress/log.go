@@ -0,0 +1,84 @@
+package progress
+
+import "sync"
+
+// Log 保存收到的所有事件，讓後來的讀者也能從頭讀取並等待新事件（例如 SSE 串流）。
+// 零值即可使用。
+type Log struct {
+	mu     sync.Mutex
+	events []Event
+	closed bool
+	notify chan struct{}
+}
+
+// Report 保存事件並喚醒等待中的讀者；Close 之後的事件會被忽略
+func (l *Log) Report(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.events = append(l.events, e)
+	l.wake()
+}
+
+// Close 表示不會再有新事件
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closed {
+		l.closed = true
+		l.wake()
+	}
+}
+
+// Since 回傳第 n 個（從 0 起算）之後的事件與 Log 是否已關閉；
+// 未關閉時另外回傳在下一個事件或關閉時會被關閉的 channel
+func (l *Log) Since(n int) ([]Event, bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var events []Event
+	if n < len(l.events) {
+		events = append(events, l.events[n:]...)
+	}
+	if l.closed {
+		return events, true, nil
+	}
+	if l.notify == nil {
+		l.notify = make(chan struct{})
+	}
+	return events, false, l.notify
+}
+
+// Last 回傳最後一個事件
+func (l *Log) Last() (Event, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) == 0 {
+		return Event{}, false
+	}
+	return l.events[len(l.events)-1], true
+}
+
+// wake 喚醒等待中的讀者，呼叫時須持有 l.mu
+func (l *Log) wake() {
+	if l.notify != nil {
+		close(l.notify)
+		l.notify = nil
+	}
+}
+
+// Forward 將 l 從頭開始的事件依序轉交給 r，直到 l 關閉
+func Forward(l *Log, r Reporter) {
+	for n := 0; ; {
+		events, closed, wait := l.Since(n)
+		for _, e := range events {
+			r.Report(e)
+		}
+		n += len(events)
+		if closed {
+			return
+		}
+		<-wait
+	}
+}
@@ -0,0 +1,193 @@
+// Package progress 定義匯出流程回報的事件，以及在主控台顯示、保存與轉送事件的 Reporter。
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Stage 為事件所屬的匯出階段
+type Stage string
+
+const (
+	StageResolve   Stage = "resolve"   // 解析版本與依賴
+	StageDownload  Stage = "download"  // 下載套件，Bytes 與 Total 為下載進度
+	StageExtract   Stage = "extract"   // 解壓縮套件
+	StageFramework Stage = "framework" // 選擇目標框架
+	StageCopy      Stage = "copy"      // 複製組件、native plugin 與 analyzer
+	StageValidate  Stage = "validate"  // 檢查匯出組件的參照
+	StagePack      Stage = "pack"      // 建立 .unitypackage 或 .tgz
+	StageDone      Stage = "done"      // 匯出完成
+)
+
+// Event 為匯出流程中的一個事件
+type Event struct {
+	Time    time.Time `json:"time"`
+	Stage   Stage     `json:"stage"`
+	Message string    `json:"message,omitempty"` // 空字串為只更新進度的事件，主控台不顯示
+	Package string    `json:"package,omitempty"`
+	Version string    `json:"version,omitempty"`
+	Bytes   int64     `json:"bytes,omitempty"`
+	Total   int64     `json:"total,omitempty"` // 0 為未知
+	Warning bool      `json:"warning,omitempty"`
+}
+
+// Reporter 接收匯出流程的事件，必須可同時由多個 goroutine 呼叫
+type Reporter interface {
+	Report(Event)
+}
+
+// ReporterFunc 讓一般函式做為 Reporter
+type ReporterFunc func(Event)
+
+// Report 呼叫 f
+func (f ReporterFunc) Report(e Event) { f(e) }
+
+// Discard 忽略所有事件
+var Discard Reporter = ReporterFunc(func(Event) {})
+
+// Emit 補上時間後將事件交給 r，r 為 nil 時忽略
+func Emit(r Reporter, e Event) {
+	if r == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	r.Report(e)
+}
+
+// Infof 回報一般訊息
+func Infof(r Reporter, stage Stage, format string, args ...interface{}) {
+	Emit(r, Event{Stage: stage, Message: fmt.Sprintf(format, args...)})
+}
+
+// Warnf 回報警告
+func Warnf(r Reporter, stage Stage, format string, args ...interface{}) {
+	Emit(r, Event{Stage: stage, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+type multi []Reporter
+
+func (m multi) Report(e Event) {
+	for _, r := range m {
+		r.Report(e)
+	}
+}
+
+// Multi 將事件依序交給每個不為 nil 的 Reporter
+func Multi(reporters ...Reporter) Reporter {
+	var m multi
+	for _, r := range reporters {
+		if r != nil {
+			m = append(m, r)
+		}
+	}
+	return m
+}
+
+// Console 將事件的訊息逐行寫入 w，警告加上 "Warning: " 前綴。
+// Live 為 true 時（通常是終端機）以同一行顯示下載進度。
+type Console struct {
+	Live bool
+
+	mu       sync.Mutex
+	w        io.Writer
+	liveLine bool
+}
+
+// NewConsole 建立寫入 w 的 Console
+func NewConsole(w io.Writer) *Console {
+	return &Console{w: w}
+}
+
+// NewTerminal 建立寫入 f 的 Console，f 為終端機時以同一行即時顯示下載進度
+func NewTerminal(f *os.File) *Console {
+	c := NewConsole(f)
+	if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		c.Live = true
+	}
+	return c
+}
+
+// Report 顯示事件
+func (c *Console) Report(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e.Message == "" {
+		if c.Live && e.Stage == StageDownload && e.Bytes > 0 {
+			fmt.Fprintf(c.w, "\r  %s %s: %s\033[K", e.Package, e.Version, FormatProgress(e.Bytes, e.Total))
+			c.liveLine = true
+		}
+		return
+	}
+	if c.liveLine {
+		fmt.Fprint(c.w, "\r\033[K")
+		c.liveLine = false
+	}
+	if e.Warning {
+		fmt.Fprintf(c.w, "Warning: %s\n", e.Message)
+	} else {
+		fmt.Fprintln(c.w, e.Message)
+	}
+}
+
+// FormatProgress 將下載進度格式化為 "1.2 MB / 3.4 MB (35%)"，total 未知時只顯示已下載的大小
+func FormatProgress(bytes, total int64) string {
+	if total <= 0 {
+		return FormatBytes(bytes)
+	}
+	return fmt.Sprintf("%s / %s (%d%%)", FormatBytes(bytes), FormatBytes(total), bytes*100/total)
+}
+
+// FormatBytes 以 B、KB、MB 或 GB 表示大小
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// reader 在讀取時回報累計的位元組數，最多每 readInterval 回報一次
+type reader struct {
+	r        io.Reader
+	reporter Reporter
+	event    Event
+	last     time.Time
+}
+
+// readInterval 為下載進度事件的最短間隔
+const readInterval = 200 * time.Millisecond
+
+// NewReader 包裝 r，讀取時以 event 為範本回報 StageDownload 的進度事件（Message 會被清空）
+func NewReader(r io.Reader, reporter Reporter, event Event) io.Reader {
+	if reporter == nil {
+		return r
+	}
+	event.Stage = StageDownload
+	event.Message = ""
+	event.Bytes = 0
+	return &reader{r: r, reporter: reporter, event: event}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.event.Bytes += int64(n)
+	if now := time.Now(); n > 0 && now.Sub(r.last) >= readInterval {
+		r.last = now
+		Emit(r.reporter, r.event)
+	}
+	return n, err
+}
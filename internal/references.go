@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/progress"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// ErrMissingReferences 表示匯出的組件參照了沒有匯出、Unity 也未內建的組件
+var ErrMissingReferences = errors.New("missing assembly references")
+
+// MissingReference 為匯出的組件中無法滿足的參照
+type MissingReference struct {
+	Assembly  string               // 參照者在套件內的路徑
+	Reference clrmeta.AssemblyName // 被參照的組件
+	Exported  string               // 匯出的同名組件版本（低於參照的版本），沒有匯出時為空字串
+}
+
+func (m MissingReference) String() string {
+	if m.Exported != "" {
+		return fmt.Sprintf("%s references %s %s, but only %s is exported", path.Base(m.Assembly), m.Reference.Name, m.Reference.Version, m.Exported)
+	}
+	return fmt.Sprintf("%s references %s %s, not provided", path.Base(m.Assembly), m.Reference.Name, m.Reference.Version)
+}
+
+// referenceSet 為匯出的組件可參照的組件：一起匯出的組件、Unity 內建的組件與使用者允許的參照
+type referenceSet struct {
+	exported  map[string]clrmeta.Version // 小寫名稱 → 匯出的最高版本
+	available map[string]bool
+	allow     []string
+}
+
+// newReferenceSet 以 staged 中所有匯出的組件建立 referenceSet；allow 中以 "*" 結尾的名稱為前綴比對
+func newReferenceSet(staged []*stagedPackage, profile unity.Profile, allow []string) *referenceSet {
+	set := &referenceSet{exported: map[string]clrmeta.Version{}, available: map[string]bool{}}
+	for _, s := range staged {
+		for _, assembly := range s.result.Assemblies {
+			key := strings.ToLower(assembly.Name)
+			if version, ok := set.exported[key]; !ok || assembly.Version.Compare(version) > 0 {
+				set.exported[key] = assembly.Version
+			}
+		}
+	}
+	for _, name := range unity.AvailableAssemblies(profile) {
+		set.available[strings.ToLower(name)] = true
+	}
+	for _, name := range allow {
+		if name = strings.TrimSpace(name); name != "" {
+			set.allow = append(set.allow, strings.ToLower(name))
+		}
+	}
+	return set
+}
+
+// missing 判斷 ref 是否無法滿足；只匯出了較低版本時 exported 為該版本
+func (set *referenceSet) missing(ref clrmeta.AssemblyName) (exported string, missing bool) {
+	key := strings.ToLower(ref.Name)
+	for _, allowed := range set.allow {
+		if allowed == key || (strings.HasSuffix(allowed, "*") && strings.HasPrefix(key, strings.TrimSuffix(allowed, "*"))) {
+			return "", false
+		}
+	}
+	if set.available[key] || unity.IsEngineAssembly(ref.Name) {
+		return "", false
+	}
+	version, ok := set.exported[key]
+	if !ok {
+		return "", true
+	}
+	if version.Compare(ref.Version) < 0 {
+		return version.String(), true
+	}
+	return "", false
+}
+
+// checkReferences 檢查 staged 中每個匯出組件的參照都由一起匯出的組件、Unity 或 opts.AllowReferences 提供，
+// 並將無法滿足的參照加入所屬套件的警告；opts.StrictReferences 時回傳 ErrMissingReferences
+func (run *exportRun) checkReferences(staged []*stagedPackage) error {
+	set := newReferenceSet(staged, run.profile, run.opts.AllowReferences)
+	if len(set.exported) == 0 {
+		return nil
+	}
+	var messages []string
+	seen := map[string]bool{}
+	for _, s := range staged {
+		for _, assembly := range s.result.Assemblies {
+			for _, ref := range assembly.References {
+				exported, missing := set.missing(ref)
+				if !missing {
+					continue
+				}
+				missingRef := MissingReference{Assembly: assembly.Path, Reference: ref, Exported: exported}
+				message := missingRef.String()
+				// Runtime/<平台> 下的 RID 專用組件通常與 lib/ 的組件有相同的參照
+				if seen[message] {
+					continue
+				}
+				seen[message] = true
+				messages = append(messages, message)
+				s.result.MissingReferences = append(s.result.MissingReferences, missingRef)
+				if !run.opts.StrictReferences {
+					progress.Warnf(run.report, progress.StageValidate, "%s", message)
+					s.result.Warnings = append(s.result.Warnings, message)
+				}
+			}
+		}
+	}
+	if len(messages) == 0 {
+		progress.Infof(run.report, progress.StageValidate, "All references of %d exported assembly(s) are provided", len(set.exported))
+		return nil
+	}
+	if run.opts.StrictReferences {
+		return fmt.Errorf("%w:\n  %s", ErrMissingReferences, strings.Join(messages, "\n  "))
+	}
+	return nil
+}
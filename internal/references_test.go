@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/clrmeta"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/unity"
+)
+
+// asmRef 回傳名稱為 name、版本為 major.minor.0.0 的組件名稱
+func asmRef(name string, major, minor uint16) clrmeta.AssemblyName {
+	return clrmeta.AssemblyName{Name: name, Version: clrmeta.Version{Major: major, Minor: minor}}
+}
+
+// testAssembly 回傳套件內 path 的組件，參照 refs
+func testAssembly(path string, name clrmeta.AssemblyName, refs ...clrmeta.AssemblyName) AssemblyResult {
+	return AssemblyResult{Path: path, Assembly: clrmeta.Assembly{AssemblyName: name, References: refs}}
+}
+
+// testStaged 回傳只有 assemblies 的 stagedPackage
+func testStaged(name string, assemblies ...AssemblyResult) *stagedPackage {
+	return &stagedPackage{name: name, result: &ExportResult{PackageID: name, Assemblies: assemblies}}
+}
+
+func TestReferenceSetMissing(t *testing.T) {
+	staged := []*stagedPackage{
+		testStaged("Foo", testAssembly("Runtime/Foo.dll", asmRef("Foo", 1, 0))),
+		testStaged("Bar",
+			testAssembly("Runtime/Bar.dll", asmRef("Bar", 2, 0)),
+			testAssembly("Runtime/Bar.Old.dll", asmRef("bar", 1, 0))),
+	}
+	set := newReferenceSet(staged, unity.DefaultProfile, []string{"Native.Sdk", " Vendor.* ", ""})
+
+	tests := []struct {
+		ref      clrmeta.AssemblyName
+		exported string
+		missing  bool
+	}{
+		{asmRef("Foo", 1, 0), "", false},
+		{asmRef("foo", 0, 9), "", false},
+		{asmRef("Foo", 1, 5), "1.0.0.0", true},
+		{asmRef("Bar", 2, 0), "", false}, // 同名組件以最高版本為準
+		{asmRef("Missing", 1, 0), "", true},
+		{asmRef("Native.Sdk", 9, 0), "", false},
+		{asmRef("native.sdk", 9, 0), "", false},
+		{asmRef("Native.Sdk.Extra", 1, 0), "", true}, // 沒有 "*" 時不是前綴比對
+		{asmRef("Vendor.Core", 1, 0), "", false},
+		{asmRef("VENDOR.Core.Extensions", 1, 0), "", false},
+		{asmRef("VendorCore", 1, 0), "", true},
+		{asmRef("netstandard", 2, 1), "", false},
+		{asmRef("UnityEngine.CoreModule", 0, 0), "", false},
+	}
+	for _, tt := range tests {
+		exported, missing := set.missing(tt.ref)
+		if exported != tt.exported || missing != tt.missing {
+			t.Errorf("missing(%s %s) = %q, %v; want %q, %v", tt.ref.Name, tt.ref.Version, exported, missing, tt.exported, tt.missing)
+		}
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	// stage 回傳 Foo 與 Bar：Foo 的 lib/ 與 RID 專用組件有相同的參照，Bar 只匯出了 Shared 1.0
+	stage := func() []*stagedPackage {
+		refs := []clrmeta.AssemblyName{asmRef("Shared", 2, 0), asmRef("Missing", 1, 0), asmRef("netstandard", 2, 0), asmRef("Allowed.Runtime", 1, 0)}
+		return []*stagedPackage{
+			testStaged("Foo",
+				testAssembly("Runtime/Foo.dll", asmRef("Foo", 1, 0), refs...),
+				testAssembly("Runtime/win-x64/Foo.dll", asmRef("Foo", 1, 0), refs...)),
+			testStaged("Bar",
+				testAssembly("Runtime/Bar.dll", asmRef("Bar", 1, 0), asmRef("Foo", 1, 0)),
+				testAssembly("Runtime/Shared.dll", asmRef("Shared", 1, 0))),
+		}
+	}
+	want := []string{
+		"Foo.dll references Shared 2.0.0.0, but only 1.0.0.0 is exported",
+		"Foo.dll references Missing 1.0.0.0, not provided",
+	}
+
+	t.Run("warnings", func(t *testing.T) {
+		staged := stage()
+		run := &exportRun{profile: unity.DefaultProfile, opts: ExportOptions{AllowReferences: []string{"Allowed.*"}}}
+		if err := run.checkReferences(staged); err != nil {
+			t.Fatal(err)
+		}
+		foo := staged[0].result
+		if !reflect.DeepEqual(foo.Warnings, want) {
+			t.Errorf("warnings = %q, want %q", foo.Warnings, want)
+		}
+		if len(foo.MissingReferences) != len(want) {
+			t.Fatalf("missing references = %+v, want %d", foo.MissingReferences, len(want))
+		}
+		if got := foo.MissingReferences[0]; got.Assembly != "Runtime/Foo.dll" || got.Exported != "1.0.0.0" || got.Reference.Name != "Shared" {
+			t.Errorf("missing reference = %+v", got)
+		}
+		if bar := staged[1].result; len(bar.Warnings) != 0 || len(bar.MissingReferences) != 0 {
+			t.Errorf("Bar warnings = %q, missing = %+v; want none", bar.Warnings, bar.MissingReferences)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		staged := stage()
+		run := &exportRun{profile: unity.DefaultProfile, opts: ExportOptions{AllowReferences: []string{"Allowed.*"}, StrictReferences: true}}
+		err := run.checkReferences(staged)
+		if !errors.Is(err, ErrMissingReferences) {
+			t.Fatalf("checkReferences() error = %v, want ErrMissingReferences", err)
+		}
+		if wantErr := "missing assembly references:\n  " + strings.Join(want, "\n  "); err.Error() != wantErr {
+			t.Errorf("checkReferences() error = %q, want %q", err, wantErr)
+		}
+		// 嚴格模式只記錄缺少的參照，不加入警告
+		foo := staged[0].result
+		if len(foo.Warnings) != 0 || len(foo.MissingReferences) != len(want) {
+			t.Errorf("warnings = %q, missing = %+v", foo.Warnings, foo.MissingReferences)
+		}
+	})
+
+	t.Run("all provided", func(t *testing.T) {
+		staged := stage()
+		run := &exportRun{profile: unity.DefaultProfile, opts: ExportOptions{AllowReferences: []string{"Allowed.*", "Missing", "Shared"}, StrictReferences: true}}
+		if err := run.checkReferences(staged); err != nil {
+			t.Errorf("checkReferences() error = %v", err)
+		}
+	})
+
+	t.Run("nothing exported", func(t *testing.T) {
+		staged := []*stagedPackage{testStaged("Empty")}
+		run := &exportRun{profile: unity.DefaultProfile, opts: ExportOptions{StrictReferences: true}}
+		if err := run.checkReferences(staged); err != nil {
+			t.Errorf("checkReferences() error = %v", err)
+		}
+	})
+}
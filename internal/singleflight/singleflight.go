@@ -0,0 +1,91 @@
+// Package singleflight 合併同一 key 同時進行的工作，並以參照計數管理結果的生命週期：
+// 工作產生的暫存檔案要等所有共用結果的呼叫端都釋放後才清理。
+package singleflight
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Group 為一組以 key 區分的工作，零值即可使用
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done    sync.WaitGroup
+	state   interface{}
+	val     interface{}
+	err     error
+	cleanup func()
+	refs    int
+}
+
+// Do 執行 fn 並回傳其結果；同一 key 已有進行中的工作時等待並共用該結果（shared 為 true）。
+// fn 回傳的 cleanup（可為 nil）在所有呼叫端都呼叫過 release 之後執行一次。
+// 工作完成後即從 Group 移除，之後相同 key 的呼叫會重新執行 fn。
+//
+// state 為新工作的共用狀態（例如進度紀錄），會傳給 fn；加入進行中的工作時使用該工作的 state。
+// join 不為 nil 時，每個呼叫端在等待結果前以工作的 state 呼叫一次。
+func (g *Group) Do(key string, state interface{}, join func(state interface{}), fn func(state interface{}) (interface{}, func(), error)) (val interface{}, release func(), shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.refs++
+		g.mu.Unlock()
+		if join != nil {
+			join(c.state)
+		}
+		c.done.Wait()
+		return c.val, g.releaser(c), true, c.err
+	}
+	c := &call{state: state, refs: 1}
+	c.done.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+	if join != nil {
+		join(state)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.err = panicError{r}
+			}
+		}()
+		c.val, c.cleanup, c.err = fn(state)
+	}()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	c.done.Done()
+	return c.val, g.releaser(c), false, c.err
+}
+
+// releaser 回傳只會生效一次的 release，最後一個釋放者執行 cleanup
+func (g *Group) releaser(c *call) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			c.refs--
+			last := c.refs == 0
+			g.mu.Unlock()
+			if last && c.cleanup != nil {
+				c.cleanup()
+			}
+		})
+	}
+}
+
+type panicError struct {
+	value interface{}
+}
+
+func (p panicError) Error() string {
+	return fmt.Sprintf("singleflight: function panicked: %v", p.value)
+}
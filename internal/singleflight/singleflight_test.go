@@ -0,0 +1,180 @@
+package singleflight
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// waitJoins 回傳 join 函式與一個在 join 被呼叫 n 次後關閉的 channel
+func waitJoins(n int) (func(interface{}), <-chan struct{}) {
+	var count int32
+	ready := make(chan struct{})
+	return func(interface{}) {
+		if atomic.AddInt32(&count, 1) == int32(n) {
+			close(ready)
+		}
+	}, ready
+}
+
+func TestDoSameKey(t *testing.T) {
+	const callers = 5
+	var g Group
+	var runs int32
+	join, joined := waitJoins(callers)
+	workDir := filepath.Join(t.TempDir(), "job")
+
+	type outcome struct {
+		val     interface{}
+		release func()
+		shared  bool
+		err     error
+	}
+	outcomes := make(chan outcome, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			val, release, shared, err := g.Do("foo", nil, join, func(interface{}) (interface{}, func(), error) {
+				atomic.AddInt32(&runs, 1)
+				if err := os.Mkdir(workDir, 0755); err != nil {
+					return nil, nil, err
+				}
+				// 等所有呼叫端都加入後才完成，確保它們共用這次工作
+				<-joined
+				return "result", func() { os.RemoveAll(workDir) }, nil
+			})
+			outcomes <- outcome{val, release, shared, err}
+		}()
+	}
+
+	var releases []func()
+	sharedCount := 0
+	for i := 0; i < callers; i++ {
+		o := <-outcomes
+		if o.err != nil {
+			t.Fatal(o.err)
+		}
+		if o.val != "result" {
+			t.Errorf("Do() = %v, want result", o.val)
+		}
+		if o.shared {
+			sharedCount++
+		}
+		releases = append(releases, o.release)
+	}
+	if runs != 1 {
+		t.Errorf("fn ran %d times, want 1", runs)
+	}
+	if sharedCount != callers-1 {
+		t.Errorf("%d callers shared the result, want %d", sharedCount, callers-1)
+	}
+
+	for i, release := range releases {
+		if _, err := os.Stat(workDir); err != nil {
+			t.Fatalf("work directory removed before release %d: %v", i+1, err)
+		}
+		release()
+		release() // 重複呼叫不會再減少參照
+	}
+	if _, err := os.Stat(workDir); !os.IsNotExist(err) {
+		t.Errorf("work directory was not removed after the last release: %v", err)
+	}
+
+	// 完成的工作已移除，相同 key 會重新執行
+	_, release, shared, err := g.Do("foo", nil, nil, func(interface{}) (interface{}, func(), error) {
+		atomic.AddInt32(&runs, 1)
+		return "again", nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if runs != 2 || shared {
+		t.Errorf("after completion: runs = %d, shared = %v, want 2 and false", runs, shared)
+	}
+}
+
+func TestDoDifferentKeys(t *testing.T) {
+	var g Group
+	var started sync.WaitGroup
+	started.Add(2)
+	results := make(chan string, 2)
+	for _, key := range []string{"foo", "bar"} {
+		key := key
+		go func() {
+			val, release, shared, err := g.Do(key, nil, nil, func(interface{}) (interface{}, func(), error) {
+				// 兩個工作都開始後才完成，不同 key 不會互相等待
+				started.Done()
+				started.Wait()
+				return key, nil, nil
+			})
+			defer release()
+			if err != nil || shared {
+				t.Errorf("Do(%s) shared = %v, error = %v", key, shared, err)
+			}
+			results <- val.(string)
+		}()
+	}
+	got := map[string]bool{<-results: true, <-results: true}
+	if !got["foo"] || !got["bar"] {
+		t.Errorf("results = %v, want foo and bar", got)
+	}
+}
+
+func TestDoState(t *testing.T) {
+	var g Group
+	join, joined := waitJoins(2)
+	var states sync.Map
+	recordJoin := func(state interface{}) {
+		states.Store(state, true)
+		join(state)
+	}
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, release, _, _ := g.Do("foo", "first", recordJoin, func(state interface{}) (interface{}, func(), error) {
+			close(started)
+			<-joined
+			return state, nil, nil
+		})
+		release()
+	}()
+	<-started
+	// 加入進行中的工作時使用該工作的 state，傳入的 state 不使用
+	val, release, shared, err := g.Do("foo", "second", recordJoin, func(interface{}) (interface{}, func(), error) {
+		t.Error("fn ran for a joined call")
+		return nil, nil, nil
+	})
+	release()
+	<-done
+	if err != nil || !shared || val != "first" {
+		t.Errorf("Do() = %v, shared %v, error %v; want first, true, nil", val, shared, err)
+	}
+	if _, ok := states.Load("second"); ok {
+		t.Error("join received the joining caller's state")
+	}
+}
+
+func TestDoErrors(t *testing.T) {
+	var g Group
+	errFailed := errors.New("failed")
+	_, release, _, err := g.Do("foo", nil, nil, func(interface{}) (interface{}, func(), error) {
+		return nil, nil, errFailed
+	})
+	release() // cleanup 為 nil 時 release 仍可呼叫
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Do() error = %v, want %v", err, errFailed)
+	}
+
+	_, release, _, err = g.Do("foo", nil, nil, func(interface{}) (interface{}, func(), error) {
+		panic("boom")
+	})
+	release()
+	if err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+		t.Errorf("Do() error = %v, want a panic error", err)
+	}
+}
@@ -0,0 +1,70 @@
+package unity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preserve 為 link.xml 中的一筆，讓 IL2CPP 的 managed code stripping 保留組件或其中的命名空間
+type Preserve struct {
+	Assembly  string
+	Namespace string // 空字串保留整個組件
+}
+
+// ParsePreserve 解析 "組件" 或 "組件/命名空間"
+func ParsePreserve(s string) (Preserve, error) {
+	assembly, namespace, _ := strings.Cut(strings.TrimSpace(s), "/")
+	p := Preserve{Assembly: strings.TrimSpace(assembly), Namespace: strings.TrimSpace(namespace)}
+	if p.Assembly == "" || (strings.Contains(s, "/") && p.Namespace == "") {
+		return Preserve{}, fmt.Errorf("invalid preserve entry %q: use <assembly> or <assembly>/<namespace>", s)
+	}
+	return p, nil
+}
+
+func (p Preserve) String() string {
+	if p.Namespace == "" {
+		return p.Assembly
+	}
+	return p.Assembly + "/" + p.Namespace
+}
+
+// reflectionPreserves 為已知以反射建立型別（序列化、DI、動態代理）的組件，
+// IL2CPP 移除沒有直接參照的型別後，這些組件會在執行時失敗
+var reflectionPreserves = []Preserve{
+	// 序列化
+	{Assembly: "Newtonsoft.Json"},
+	{Assembly: "System.Text.Json", Namespace: "System.Text.Json.Serialization.Converters"},
+	{Assembly: "YamlDotNet"},
+	{Assembly: "protobuf-net"},
+	{Assembly: "protobuf-net.Core"},
+	{Assembly: "Google.Protobuf"},
+	{Assembly: "MessagePack"},
+	{Assembly: "MessagePack.Annotations"},
+	{Assembly: "CsvHelper"},
+	// DI 容器與設定繫結
+	{Assembly: "Microsoft.Extensions.DependencyInjection"},
+	{Assembly: "Microsoft.Extensions.Options"},
+	{Assembly: "Microsoft.Extensions.Configuration.Binder"},
+	{Assembly: "Autofac"},
+	{Assembly: "Ninject"},
+	{Assembly: "SimpleInjector"},
+	// 動態代理與物件對應
+	{Assembly: "Castle.Core"},
+	{Assembly: "AutoMapper"},
+	{Assembly: "Dapper"},
+	// 以設定檔載入 sink 與 enricher
+	{Assembly: "Serilog"},
+	{Assembly: "Serilog.Settings.Configuration"},
+}
+
+// KnownPreserves 回傳 assemblies 中已知需要保留的組件
+func KnownPreserves(assemblies []string) []Preserve {
+	exported := toSet(assemblies)
+	var preserves []Preserve
+	for _, p := range reflectionPreserves {
+		if exported[strings.ToLower(p.Assembly)] {
+			preserves = append(preserves, p)
+		}
+	}
+	return preserves
+}
@@ -0,0 +1,179 @@
+package unity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// APICompatibilityLevel 為 Unity Player Settings 中的 Api Compatibility Level
+type APICompatibilityLevel string
+
+const (
+	NetStandard20 APICompatibilityLevel = "netstandard2.0"
+	NetStandard21 APICompatibilityLevel = "netstandard2.1"
+	NetFramework  APICompatibilityLevel = "netframework" // .NET Framework 4.x
+)
+
+// Version 為 Unity 編輯器版本，只比較前兩段（例如 2021.3、6000.0）
+type Version struct {
+	Major int
+	Minor int
+}
+
+// ParseVersion 解析 "2021.3"、"2021.3.5f1"、"6000.0.23f1" 等 Unity 版本字串
+func ParseVersion(s string) (Version, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ".", 3)
+	if len(parts) < 2 {
+		return Version{}, fmt.Errorf("invalid Unity version %q", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid Unity version %q", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid Unity version %q", s)
+	}
+	return Version{Major: major, Minor: minor}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// AtLeast 判斷版本是否不低於 other
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// ParseAPICompatibilityLevel 接受 "netstandard2.1"、".NET Standard 2.1"、"net4x"、".NET Framework" 等寫法
+func ParseAPICompatibilityLevel(s string) (APICompatibilityLevel, error) {
+	normalized := strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(s)))
+	normalized = strings.TrimPrefix(normalized, ".")
+	switch normalized {
+	case "netstandard2.0", "netstandard20", "standard2.0":
+		return NetStandard20, nil
+	case "netstandard2.1", "netstandard21", "standard2.1", "netstandard":
+		return NetStandard21, nil
+	case "netframework", "net4x", "net4.x", "net471", "net48", "framework":
+		return NetFramework, nil
+	}
+	return "", fmt.Errorf("unknown API compatibility level %q (expected netstandard2.0, netstandard2.1 or netframework)", s)
+}
+
+// Profile 描述匯出目標的 Unity 版本與 API compatibility level
+type Profile struct {
+	UnityVersion Version
+	APILevel     APICompatibilityLevel
+}
+
+// DefaultProfile 為未指定時使用的目標（Unity 2021.3 LTS, .NET Standard 2.1）
+var DefaultProfile = Profile{UnityVersion: Version{Major: 2021, Minor: 3}, APILevel: NetStandard21}
+
+var (
+	// minSupportedVersion 為支援的最低 Unity 版本（.NET 4.x 等效 runtime）
+	minSupportedVersion = Version{Major: 2018, Minor: 1}
+	// netStandard21Since 為 .NET Standard 2.1 取代 .NET Standard 2.0 的版本
+	netStandard21Since = Version{Major: 2021, Minor: 2}
+)
+
+// ParseProfile 由 Unity 版本與 API level 字串建立 Profile。
+// Unity 版本為空時使用 DefaultProfile 的版本；API level 為空時使用該 Unity 版本預設的 .NET Standard。
+func ParseProfile(unityVersion, apiLevel string) (Profile, error) {
+	profile := DefaultProfile
+	if unityVersion != "" {
+		v, err := ParseVersion(unityVersion)
+		if err != nil {
+			return Profile{}, err
+		}
+		profile.UnityVersion = v
+	}
+
+	if apiLevel != "" {
+		level, err := ParseAPICompatibilityLevel(apiLevel)
+		if err != nil {
+			return Profile{}, err
+		}
+		profile.APILevel = level
+	} else if profile.UnityVersion.AtLeast(netStandard21Since) {
+		profile.APILevel = NetStandard21
+	} else {
+		profile.APILevel = NetStandard20
+	}
+
+	if err := profile.Validate(); err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// Validate 檢查該 Unity 版本是否提供此 API level
+func (p Profile) Validate() error {
+	if !p.UnityVersion.AtLeast(minSupportedVersion) {
+		return fmt.Errorf("Unity %s is not supported (requires %s or newer)", p.UnityVersion, minSupportedVersion)
+	}
+	switch p.APILevel {
+	case NetStandard21:
+		if !p.UnityVersion.AtLeast(netStandard21Since) {
+			return fmt.Errorf("%s requires Unity %s or newer; use %s for Unity %s", NetStandard21, netStandard21Since, NetStandard20, p.UnityVersion)
+		}
+	case NetStandard20:
+		if p.UnityVersion.AtLeast(netStandard21Since) {
+			return fmt.Errorf("Unity %s replaced %s with %s", p.UnityVersion, NetStandard20, NetStandard21)
+		}
+	case NetFramework:
+	default:
+		return fmt.Errorf("unknown API compatibility level %q", p.APILevel)
+	}
+	return nil
+}
+
+// TargetFrameworks 回傳此 Profile 可使用的 NuGet 目標框架，依優先順序排列。
+// 第一個為主要目標，其後為 Unity 仍可載入的 fallback（例如 .NET Standard 2.1 下的 .NET Framework 組件）。
+func (p Profile) TargetFrameworks() []string {
+	modern := p.UnityVersion.AtLeast(netStandard21Since)
+	switch p.APILevel {
+	case NetStandard20:
+		return []string{"netstandard2.0", "net471"}
+	case NetFramework:
+		if modern {
+			return []string{"net48", "netstandard2.1"}
+		}
+		return []string{"net471"}
+	}
+	return []string{"netstandard2.1", "net48"}
+}
+
+// roslynVersions 為各 Unity 版本內建的 Roslyn 編譯器版本（同樣以 Major.Minor 表示），依 Unity 版本由新到舊排列
+var roslynVersions = []struct {
+	since  Version
+	roslyn Version
+}{
+	{Version{Major: 6000, Minor: 0}, Version{Major: 4, Minor: 3}},
+	{Version{Major: 2022, Minor: 2}, Version{Major: 4, Minor: 1}},
+	{Version{Major: 2021, Minor: 2}, Version{Major: 3, Minor: 8}},
+	{Version{Major: 2020, Minor: 2}, Version{Major: 3, Minor: 5}},
+}
+
+// RoslynVersion 回傳此 Unity 版本載入 Roslyn analyzer 時使用的編譯器版本；不支援 analyzer 的版本回傳 false
+func (p Profile) RoslynVersion() (Version, bool) {
+	for _, entry := range roslynVersions {
+		if p.UnityVersion.AtLeast(entry.since) {
+			return entry.roslyn, true
+		}
+	}
+	return Version{}, false
+}
+
+// IsZero 是否為未設定的 Profile
+func (p Profile) IsZero() bool {
+	return p == Profile{}
+}
+
+func (p Profile) String() string {
+	return fmt.Sprintf("Unity %s (%s)", p.UnityVersion, p.APILevel)
+}
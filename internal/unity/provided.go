@@ -0,0 +1,308 @@
+package unity
+
+import "strings"
+
+// providedEntry 為排除表中的一筆：自 Since 版本起，在 Levels 下 Unity 已內建這些組件
+type providedEntry struct {
+	Since      Version
+	Levels     []APICompatibilityLevel // 空代表所有 API level
+	Assemblies []string
+}
+
+// providedAssemblies 為 Unity 編輯器已內建的組件與 NuGet 套件 (BCL / facade)。
+// 這些組件若隨依賴一起匯出，會與 Unity 內建的版本衝突。
+var providedAssemblies = []providedEntry{
+	{
+		// 只含 build 資產的 meta-package
+		Since: Version{2018, 1},
+		Assemblies: []string{
+			"NETStandard.Library",
+			"Microsoft.NETCore.Platforms",
+			"Microsoft.NETCore.Targets",
+			"runtime.native.System",
+		},
+	},
+	{
+		// mscorlib / netstandard 與 .NET Standard 2.0 的 facade
+		Since: Version{2018, 1},
+		Assemblies: []string{
+			"mscorlib",
+			"netstandard",
+			"System",
+			"System.Core",
+			"Microsoft.Win32.Primitives",
+			"System.AppContext",
+			"System.Collections",
+			"System.Collections.Concurrent",
+			"System.Collections.NonGeneric",
+			"System.Collections.Specialized",
+			"System.ComponentModel",
+			"System.ComponentModel.Primitives",
+			"System.ComponentModel.TypeConverter",
+			"System.Console",
+			"System.Diagnostics.Debug",
+			"System.Diagnostics.Tools",
+			"System.Diagnostics.Tracing",
+			"System.Dynamic.Runtime",
+			"System.Globalization",
+			"System.Globalization.Extensions",
+			"System.IO",
+			"System.IO.Compression",
+			"System.IO.FileSystem",
+			"System.IO.FileSystem.Primitives",
+			"System.Linq",
+			"System.Linq.Expressions",
+			"System.Linq.Queryable",
+			"System.Net.Http",
+			"System.Net.Primitives",
+			"System.Net.Sockets",
+			"System.ObjectModel",
+			"System.Reflection",
+			"System.Reflection.Emit",
+			"System.Reflection.Emit.ILGeneration",
+			"System.Reflection.Emit.Lightweight",
+			"System.Reflection.Extensions",
+			"System.Reflection.Primitives",
+			"System.Resources.ResourceManager",
+			"System.Runtime",
+			"System.Runtime.Extensions",
+			"System.Runtime.Handles",
+			"System.Runtime.InteropServices",
+			"System.Runtime.InteropServices.RuntimeInformation",
+			"System.Runtime.Numerics",
+			"System.Runtime.Serialization.Primitives",
+			"System.Security.Cryptography.Algorithms",
+			"System.Security.Cryptography.Encoding",
+			"System.Security.Cryptography.Primitives",
+			"System.Security.Cryptography.X509Certificates",
+			"System.Text.Encoding",
+			"System.Text.Encoding.Extensions",
+			"System.Text.RegularExpressions",
+			"System.Threading",
+			"System.Threading.Tasks",
+			"System.Threading.Thread",
+			"System.Threading.ThreadPool",
+			"System.Threading.Timer",
+			"System.ValueTuple",
+			"System.Xml.ReaderWriter",
+			"System.Xml.XDocument",
+			"System.Xml.XmlDocument",
+			"System.Xml.XmlSerializer",
+		},
+	},
+	{
+		// Unity 2021.2 起兩種 API level 皆內建 Span<T> 等型別
+		Since:  Version{2021, 2},
+		Levels: []APICompatibilityLevel{NetStandard21, NetFramework},
+		Assemblies: []string{
+			"System.Buffers",
+			"System.Memory",
+			"System.Numerics.Vectors",
+			"System.Runtime.CompilerServices.Unsafe",
+			"System.Threading.Tasks.Extensions",
+		},
+	},
+}
+
+// referenceAssemblies 為 Unity 內建、但不在排除表中的框架組件（其餘的 .NET Standard facade 與
+// .NET Framework 組件）。匯出時不排除同名的套件，只用於檢查匯出組件的參照是否都有提供
+var referenceAssemblies = []providedEntry{
+	{
+		// .NET Standard 2.0 的其他 facade 與 .NET Framework 相容組件
+		Since: Version{2018, 1},
+		Assemblies: []string{
+			"System.ComponentModel.Composition",
+			"System.ComponentModel.EventBasedAsync",
+			"System.Data",
+			"System.Data.Common",
+			"System.Diagnostics.Contracts",
+			"System.Diagnostics.FileVersionInfo",
+			"System.Diagnostics.Process",
+			"System.Diagnostics.StackTrace",
+			"System.Diagnostics.TextWriterTraceListener",
+			"System.Diagnostics.TraceSource",
+			"System.Drawing",
+			"System.Drawing.Primitives",
+			"System.Globalization.Calendars",
+			"System.IO.Compression.FileSystem",
+			"System.IO.Compression.ZipFile",
+			"System.IO.FileSystem.DriveInfo",
+			"System.IO.FileSystem.Watcher",
+			"System.IO.IsolatedStorage",
+			"System.IO.MemoryMappedFiles",
+			"System.IO.Pipes",
+			"System.IO.UnmanagedMemoryStream",
+			"System.Linq.Parallel",
+			"System.Net",
+			"System.Net.NameResolution",
+			"System.Net.NetworkInformation",
+			"System.Net.Ping",
+			"System.Net.Requests",
+			"System.Net.Security",
+			"System.Net.WebHeaderCollection",
+			"System.Net.WebSockets",
+			"System.Net.WebSockets.Client",
+			"System.Numerics",
+			"System.Resources.Reader",
+			"System.Resources.Writer",
+			"System.Runtime.CompilerServices.VisualC",
+			"System.Runtime.Serialization",
+			"System.Runtime.Serialization.Formatters",
+			"System.Runtime.Serialization.Json",
+			"System.Runtime.Serialization.Xml",
+			"System.Security.Claims",
+			"System.Security.Cryptography.Csp",
+			"System.Security.Principal",
+			"System.Security.SecureString",
+			"System.ServiceModel.Web",
+			"System.Threading.Overlapped",
+			"System.Threading.Tasks.Parallel",
+			"System.Transactions",
+			"System.Web",
+			"System.Windows",
+			"System.Xml",
+			"System.Xml.Linq",
+			"System.Xml.Serialization",
+			"System.Xml.XPath",
+			"System.Xml.XPath.XDocument",
+		},
+	},
+	{
+		Since:      Version{2021, 2},
+		Levels:     []APICompatibilityLevel{NetStandard21, NetFramework},
+		Assemblies: []string{"System.Reflection.DispatchProxy"},
+	},
+	{
+		// .NET Framework API level 額外提供的組件
+		Since:  Version{2018, 1},
+		Levels: []APICompatibilityLevel{NetFramework},
+		Assemblies: []string{
+			"Microsoft.CSharp",
+			"System.ComponentModel.DataAnnotations",
+			"System.Configuration",
+			"System.Data.DataSetExtensions",
+			"System.IdentityModel",
+			"System.Runtime.Caching",
+			"System.Runtime.Serialization.Formatters.Soap",
+			"System.Security",
+			"System.ServiceModel",
+			"System.Web.Services",
+		},
+	},
+}
+
+// ProvidedAssemblies 回傳指定 Profile 下 Unity 已內建的組件名稱
+func ProvidedAssemblies(profile Profile) []string {
+	return assembliesFor(providedAssemblies, profile)
+}
+
+// AvailableAssemblies 回傳指定 Profile 下匯出的組件可參照的 Unity 內建組件，包含 ProvidedAssemblies；
+// UnityEngine 與 UnityEditor 的模組見 IsEngineAssembly
+func AvailableAssemblies(profile Profile) []string {
+	return append(ProvidedAssemblies(profile), assembliesFor(referenceAssemblies, profile)...)
+}
+
+// IsEngineAssembly 判斷組件是否為 UnityEngine 或 UnityEditor（含其模組，如 UnityEngine.CoreModule）
+func IsEngineAssembly(name string) bool {
+	for _, prefix := range []string{"UnityEngine", "UnityEditor"} {
+		if strings.EqualFold(name, prefix) || (len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)+1], prefix+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+func assembliesFor(entries []providedEntry, profile Profile) []string {
+	var names []string
+	for _, entry := range entries {
+		if !profile.UnityVersion.AtLeast(entry.Since) || !entry.appliesTo(profile.APILevel) {
+			continue
+		}
+		names = append(names, entry.Assemblies...)
+	}
+	return names
+}
+
+func (e providedEntry) appliesTo(level APICompatibilityLevel) bool {
+	if len(e.Levels) == 0 {
+		return true
+	}
+	for _, l := range e.Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// AssemblyFilter 決定哪些組件或套件不應匯出。
+// 內建排除表可被 allow 清單覆寫；deny 清單中的名稱一律排除。名稱比對不分大小寫。
+type AssemblyFilter struct {
+	provided map[string]bool
+	allow    map[string]bool
+	deny     map[string]bool
+}
+
+// NewAssemblyFilter 依 Profile 的內建排除表與使用者的 allow / deny 清單建立 filter
+func NewAssemblyFilter(profile Profile, allow, deny []string) *AssemblyFilter {
+	return &AssemblyFilter{
+		provided: toSet(ProvidedAssemblies(profile)),
+		allow:    toSet(allow),
+		deny:     toSet(deny),
+	}
+}
+
+// Excludes 判斷組件名稱或套件 id 是否應排除
+func (f *AssemblyFilter) Excludes(name string) bool {
+	if f == nil {
+		return false
+	}
+	key := strings.ToLower(name)
+	if f.deny[key] {
+		return true
+	}
+	return f.provided[key] && !f.allow[key]
+}
+
+// Denies 判斷組件名稱或套件 id 是否在使用者的 deny 清單中
+func (f *AssemblyFilter) Denies(name string) bool {
+	return f != nil && f.deny[strings.ToLower(name)]
+}
+
+// WithAllowed 回傳另外允許 names 的副本（只覆寫內建排除表，deny 清單仍然優先）
+func (f *AssemblyFilter) WithAllowed(names ...string) *AssemblyFilter {
+	if f == nil {
+		return nil
+	}
+	allow := make(map[string]bool, len(f.allow)+len(names))
+	for name := range f.allow {
+		allow[name] = true
+	}
+	for name := range toSet(names) {
+		allow[name] = true
+	}
+	return &AssemblyFilter{provided: f.provided, allow: allow, deny: f.deny}
+}
+
+// IsProvided 判斷組件是否由 Unity 內建
+func (f *AssemblyFilter) IsProvided(name string) bool {
+	return f != nil && f.provided[strings.ToLower(name)]
+}
+
+// Reason 回傳排除的原因，供輸出訊息使用
+func (f *AssemblyFilter) Reason(name string) string {
+	if f.Denies(name) {
+		return "denied by user"
+	}
+	return "provided by Unity"
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			set[strings.ToLower(name)] = true
+		}
+	}
+	return set
+}
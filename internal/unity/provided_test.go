@@ -0,0 +1,33 @@
+package unity
+
+import "testing"
+
+func TestAssemblyFilterWithAllowed(t *testing.T) {
+	filter := NewAssemblyFilter(DefaultProfile, nil, []string{"Denied.Pkg"})
+	root := filter.WithAllowed("System.Console", "Denied.Pkg")
+
+	tests := []struct {
+		name           string
+		filter, allows bool // filter 與 root 是否匯出
+	}{
+		{"System.Console", false, true},
+		{"system.console", false, true},
+		{"System.Collections", false, false}, // root 中的其他內建組件仍然排除
+		{"Denied.Pkg", false, false},         // deny 清單優先於 allow
+		{"Extra.Helper", true, true},
+	}
+	for _, tt := range tests {
+		if got := !filter.Excludes(tt.name); got != tt.filter {
+			t.Errorf("filter exports %s = %v, want %v", tt.name, got, tt.filter)
+		}
+		if got := !root.Excludes(tt.name); got != tt.allows {
+			t.Errorf("WithAllowed exports %s = %v, want %v", tt.name, got, tt.allows)
+		}
+	}
+	if !filter.Denies("denied.pkg") || filter.Denies("System.Console") {
+		t.Error("Denies should only match the deny list")
+	}
+	if (*AssemblyFilter)(nil).WithAllowed("x") != nil || (*AssemblyFilter)(nil).Denies("x") {
+		t.Error("a nil filter should allow everything")
+	}
+}
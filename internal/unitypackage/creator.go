@@ -3,81 +3,196 @@ package unitypackage
 import (
 	"archive/tar"
 	"compress/gzip"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Harrison-Dev/nuget-2-dll-go/internal/utils"
 )
 
-// CreateUnityPackageFromExport 掃描 export/<packageName> 下所有檔案，打包成 .unitypackage
-func CreateUnityPackageFromExport(exportDir, packageName, outPackageName string) error {
-	// 收集所有檔案
-	var files []string
+// PackOptions 為打包時的資產設定
+type PackOptions struct {
+	// PreviousGUIDs 中已有的資產沿用舊 GUID（可為 nil）
+	PreviousGUIDs GUIDMap
+	// Plugins 以 export 目錄內的相對路徑（"/" 分隔）指定個別 plugin 的 PluginImporter 設定
+	Plugins map[string]PluginSettings
+	// DefaultPlugin 為未列於 Plugins 的 plugin 使用的設定，nil 使用 DefaultPluginSettings
+	DefaultPlugin *PluginSettings
+}
+
+// asset 為打包時的一個檔案或資料夾
+type asset struct {
+	rel   string // export 目錄內的相對路徑（"/" 分隔），root 資料夾為 ""
+	path  string
+	isDir bool
+	guid  string
+	meta  []byte
+}
+
+// collectAssets 掃描 exportDir，為每個檔案與資料夾（含 root）決定 GUID 並產生 meta。
+// 既有 GUID 依序以 lookupRoots 中的名稱作為資產路徑的根目錄查找。
+func collectAssets(exportDir, packageName string, lookupRoots []string, opts PackOptions) ([]asset, error) {
+	var assets []asset
 	err := filepath.Walk(exportDir, func(path string, info os.FileInfo, wErr error) error {
 		if wErr != nil {
 			return wErr
 		}
-		if !info.IsDir() {
-			files = append(files, path)
+		rel, err := filepath.Rel(exportDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
 		}
+
+		guid, ok := "", false
+		for _, root := range lookupRoots {
+			assetPath := root
+			if rel != "" {
+				assetPath += "/" + rel
+			}
+			if guid, ok = opts.PreviousGUIDs.Lookup(assetPath); ok {
+				break
+			}
+		}
+		if !ok {
+			guid = utils.AssetGUID(packageName, rel)
+		}
+
+		settings := opts.DefaultPlugin
+		if s, ok := opts.Plugins[rel]; ok {
+			settings = &s
+		}
+		assets = append(assets, asset{
+			rel:   rel,
+			path:  path,
+			isDir: info.IsDir(),
+			guid:  guid,
+			meta:  GenerateMeta(guid, rel, info.IsDir(), settings),
+		})
 		return nil
 	})
-	if err != nil {
-		return err
-	}
-
-	outFile, err := os.Create(outPackageName)
-	if err != nil {
-		return err
-	}
-	defer outFile.Close()
+	return assets, err
+}
 
-	gzipWriter := gzip.NewWriter(outFile)
-	defer gzipWriter.Close()
+// CreateUnityPackageFromExport 掃描 export/<packageName> 下所有檔案與資料夾，打包成 .unitypackage。
+// GUID 依套件名稱與資產路徑固定產生，meta 依資產類型使用對應的 importer。
+func CreateUnityPackageFromExport(exportDir, packageName, outPackageName string, opts PackOptions) error {
+	return CreateUnityPackage(outPackageName, DefaultAssetRoot, []PackageDir{{Dir: exportDir, Name: packageName, Options: opts}})
+}
 
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
+// DefaultAssetRoot 為 .unitypackage 預設的匯入位置
+const DefaultAssetRoot = "Assets"
 
-	for _, f := range files {
-		rel, err := filepath.Rel(exportDir, f)
-		if err != nil {
-			return err
-		}
-		unityPath := filepath.ToSlash(filepath.Join("Assets", packageName, rel))
-		content, err := os.ReadFile(f)
-		if err != nil {
-			return err
+// NormalizeAssetRoot 將匯入位置整理為 "Assets" 或 "Assets/..." 形式的路徑；
+// 空字串為 DefaultAssetRoot，不以 Assets 開頭的相對路徑會放在 Assets 下
+func NormalizeAssetRoot(root string) (string, error) {
+	root = strings.Trim(strings.ReplaceAll(strings.TrimSpace(root), "\\", "/"), "/")
+	if root == "" {
+		return DefaultAssetRoot, nil
+	}
+	for _, part := range strings.Split(root, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid asset root %q: use a path such as Assets/Plugins/NuGet", root)
 		}
+	}
+	if root != DefaultAssetRoot && !strings.HasPrefix(root, DefaultAssetRoot+"/") {
+		root = DefaultAssetRoot + "/" + root
+	}
+	return root, nil
+}
 
-		guid := utils.GenGUID()
+// PackageDir 為打包進 .unitypackage 的一個 export 目錄
+type PackageDir struct {
+	Dir     string // export 目錄
+	Name    string // 套件名稱，決定 GUID 與匯入後的資料夾名稱
+	Options PackOptions
+}
 
-		// 寫入 asset
-		err = writeTarFile(tarWriter, guid+"/asset", content)
+// CreateUnityPackage 將 packages 打包成一個 .unitypackage，每個目錄匯入到 <assetRoot>/<Name>；
+// assetRoot 須已由 NormalizeAssetRoot 整理過，空字串為 DefaultAssetRoot
+func CreateUnityPackage(outPackageName, assetRoot string, packages []PackageDir) error {
+	if assetRoot == "" {
+		assetRoot = DefaultAssetRoot
+	}
+	collected := make([][]asset, len(packages))
+	for i, pkg := range packages {
+		assets, err := collectAssets(pkg.Dir, pkg.Name, []string{pkg.Name}, pkg.Options)
 		if err != nil {
 			return err
 		}
+		collected[i] = assets
+	}
 
-		// 寫入 asset.meta
-		metaContent := utils.GenerateMeta(guid)
-		err = writeTarFile(tarWriter, guid+"/asset.meta", metaContent)
-		if err != nil {
-			return err
-		}
+	return createTarGz(outPackageName, func(tarWriter *tar.Writer) error {
+		for i, pkg := range packages {
+			for _, a := range collected[i] {
+				unityPath := assetRoot + "/" + pkg.Name
+				if a.rel != "" {
+					unityPath += "/" + a.rel
+				}
 
-		// 寫入 pathname
-		err = writeTarFile(tarWriter, guid+"/pathname", []byte(unityPath))
-		if err != nil {
-			return err
+				// 寫入 asset（資料夾沒有 asset）
+				if !a.isDir {
+					content, err := os.ReadFile(a.path)
+					if err != nil {
+						return err
+					}
+					err = writeTarFile(tarWriter, a.guid+"/asset", content)
+					if err != nil {
+						return err
+					}
+				}
+
+				// 寫入 asset.meta
+				err := writeTarFile(tarWriter, a.guid+"/asset.meta", a.meta)
+				if err != nil {
+					return err
+				}
+
+				// 寫入 pathname
+				err = writeTarFile(tarWriter, a.guid+"/pathname", []byte(unityPath))
+				if err != nil {
+					return err
+				}
+			}
 		}
+		return nil
+	})
+}
+
+// createTarGz 建立 outPath 並以 write 寫入 gzip 壓縮的 tar，再依序關閉 tar、gzip 與檔案；
+// 任何一步失敗（包括寫出最後資料的 Close）都回傳錯誤並刪除不完整的檔案
+func createTarGz(outPath string, write func(*tar.Writer) error) error {
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		return err
 	}
+	gzipWriter := gzip.NewWriter(outFile)
+	tarWriter := tar.NewWriter(gzipWriter)
 
-	return nil
+	err = write(tarWriter)
+	if closeErr := tarWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := gzipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outPath)
+	}
+	return err
 }
 
 func writeTarFile(tw *tar.Writer, name string, data []byte) error {
 	header := &tar.Header{
 		Name: name,
-		Mode: 0600,
+		Mode: 0644,
 		Size: int64(len(data)),
 	}
 	if err := tw.WriteHeader(header); err != nil {
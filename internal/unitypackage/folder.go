@@ -0,0 +1,22 @@
+package unitypackage
+
+import "os"
+
+// WriteFolderMetas 在 exportDir 中每個檔案與資料夾（根目錄除外）旁寫入 .meta，
+// 讓資料夾可直接放進 Unity 專案的 Packages/ 或 Assets/。
+// 既有 GUID 的查找方式與 CreateUPMTarball 相同。
+func WriteFolderMetas(exportDir, packageName, upmName string, opts PackOptions) error {
+	assets, err := collectAssets(exportDir, packageName, []string{upmName, packageName}, opts)
+	if err != nil {
+		return err
+	}
+	for _, a := range assets {
+		if a.rel == "" {
+			continue
+		}
+		if err := os.WriteFile(a.path+".meta", a.meta, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package unitypackage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GUIDMap 為既有資產的 pathname 與 GUID 對應，用於升級時保留 Unity 場景與 prefab 的參照
+type GUIDMap map[string]string
+
+// LoadGUIDMap 從先前匯出的 .unitypackage、UPM 套件 .tgz 或 Unity 專案（或其中任一資料夾）讀取既有的 GUID
+func LoadGUIDMap(path string) (GUIDMap, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadGUIDsFromProject(path)
+	}
+	return loadGUIDsFromPackage(path)
+}
+
+// Lookup 以資產路徑尋找既有 GUID；assetPath 可為完整 pathname 或其結尾（例如 "<套件>/Runtime/Foo.dll"），
+// 因此使用者把套件移到 Assets/Plugins 之類的位置後仍能對應
+func (m GUIDMap) Lookup(assetPath string) (string, bool) {
+	assetPath = filepath.ToSlash(assetPath)
+	if guid, ok := m[assetPath]; ok {
+		return guid, true
+	}
+
+	var match, matchPath string
+	for pathname, guid := range m {
+		if !strings.HasSuffix(pathname, "/"+assetPath) {
+			continue
+		}
+		// 多個符合時選最短（最接近根目錄）的路徑，使結果穩定
+		if match == "" || len(pathname) < len(matchPath) || (len(pathname) == len(matchPath) && pathname < matchPath) {
+			match, matchPath = guid, pathname
+		}
+	}
+	return match, match != ""
+}
+
+func loadGUIDsFromPackage(path string) (GUIDMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a unitypackage or UPM tarball: %v", path, err)
+	}
+	defer gzipReader.Close()
+
+	guids := GUIDMap{}
+	upmGUIDs := map[string]string{} // UPM 套件 package/ 內的相對路徑 → GUID
+	upmName := ""
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(filepath.ToSlash(header.Name), "./")
+		if rel := strings.TrimPrefix(name, "package/"); rel != name {
+			switch {
+			case rel == "package.json":
+				var manifest struct {
+					Name string `json:"name"`
+				}
+				if err := json.NewDecoder(tarReader).Decode(&manifest); err == nil {
+					upmName = manifest.Name
+				}
+			case strings.HasSuffix(rel, ".meta"):
+				content, err := io.ReadAll(tarReader)
+				if err != nil {
+					return nil, err
+				}
+				if guid := parseMetaGUID(content); guid != "" {
+					upmGUIDs[strings.TrimSuffix(rel, ".meta")] = guid
+				}
+			}
+			continue
+		}
+
+		dir, base := filepath.Split(name)
+		if base != "pathname" {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		// pathname 檔第一行為資產路徑，後續行可能帶有其他資訊
+		pathname := strings.TrimSpace(strings.SplitN(string(content), "\n", 2)[0])
+		guids[pathname] = strings.TrimSuffix(dir, "/")
+	}
+
+	// UPM 套件安裝後位於 Packages/<套件名稱>/
+	if upmName == "" {
+		upmName = "package"
+	}
+	for rel, guid := range upmGUIDs {
+		guids["Packages/"+upmName+"/"+rel] = guid
+	}
+	return guids, nil
+}
+
+func loadGUIDsFromProject(root string) (GUIDMap, error) {
+	guids := GUIDMap{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, wErr error) error {
+		if wErr != nil {
+			return wErr
+		}
+		if info.IsDir() {
+			switch info.Name() {
+			case "Library", "Temp", "Logs", "obj", ".git":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".meta") {
+			return nil
+		}
+
+		guid, err := readMetaGUID(path)
+		if err != nil || guid == "" {
+			return err
+		}
+		rel, err := filepath.Rel(root, strings.TrimSuffix(path, ".meta"))
+		if err != nil {
+			return err
+		}
+		guids[filepath.ToSlash(rel)] = guid
+		return nil
+	})
+	return guids, err
+}
+
+func readMetaGUID(metaPath string) (string, error) {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return "", err
+	}
+	return parseMetaGUID(data), nil
+}
+
+// parseMetaGUID 取出 .meta 內容中的 guid，找不到時回傳空字串
+func parseMetaGUID(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "guid:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "guid:"))
+		}
+	}
+	return ""
+}
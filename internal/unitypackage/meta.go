@@ -1,5 +1,397 @@
 package unitypackage
 
-// 此檔用於未來若要對 meta 生成做更多處理時，可在此擴充。
-// 目前meta生成在 utils/guid.go:GenerateMeta 中處理。
-// 如需更複雜邏輯，可將 GenerateMeta 移至此檔案。
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BuildTarget 為 Unity 的 build target，名稱與 meta 檔 platformData 中的一致
+type BuildTarget string
+
+const (
+	TargetEditor           BuildTarget = "Editor"
+	TargetWin              BuildTarget = "Win"
+	TargetWin64            BuildTarget = "Win64"
+	TargetOSXUniversal     BuildTarget = "OSXUniversal"
+	TargetLinux64          BuildTarget = "Linux64"
+	TargetAndroid          BuildTarget = "Android"
+	TargetIOS              BuildTarget = "iOS"
+	TargetWebGL            BuildTarget = "WebGL"
+	TargetWindowsStoreApps BuildTarget = "WindowsStoreApps"
+)
+
+// buildTargets 依 Unity 寫入 meta 的順序列出支援的平台
+var buildTargets = []BuildTarget{
+	TargetAndroid,
+	TargetEditor,
+	TargetLinux64,
+	TargetOSXUniversal,
+	TargetWebGL,
+	TargetWin,
+	TargetWin64,
+	TargetWindowsStoreApps,
+	TargetIOS,
+}
+
+// ParseBuildTarget 解析平台名稱（不分大小寫），並接受 windows、macos、linux 等常見別名
+func ParseBuildTarget(name string) (BuildTarget, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, target := range buildTargets {
+		if lower == strings.ToLower(string(target)) {
+			return target, nil
+		}
+	}
+	switch lower {
+	case "windows", "win-x64":
+		return TargetWin64, nil
+	case "win32", "win-x86":
+		return TargetWin, nil
+	case "osx", "macos", "mac":
+		return TargetOSXUniversal, nil
+	case "linux":
+		return TargetLinux64, nil
+	case "iphone":
+		return TargetIOS, nil
+	case "uwp", "wsa":
+		return TargetWindowsStoreApps, nil
+	}
+	return "", fmt.Errorf("unknown Unity build target %q", name)
+}
+
+// PlatformSetting 為單一平台的啟用設定
+type PlatformSetting struct {
+	Target BuildTarget
+	CPU    string // 空字串使用該平台的預設值
+	OS     string // 僅 Editor 使用：AnyOS、Windows、OSX、Linux
+}
+
+// PluginSettings 為 PluginImporter 的設定
+type PluginSettings struct {
+	IsPreloaded            bool
+	ValidateReferences     bool
+	IsExplicitlyReferenced bool
+	DefineConstraints      []string
+
+	// AnyPlatform 為 true 時啟用於所有平台（Exclude 中的除外）；否則只啟用於 Include 中的平台
+	AnyPlatform bool
+	Exclude     []BuildTarget
+	Include     []PlatformSetting
+
+	Labels []string
+}
+
+// DefaultPluginSettings 為一般 managed DLL 的設定：所有平台皆啟用並檢查參照
+func DefaultPluginSettings() PluginSettings {
+	return PluginSettings{ValidateReferences: true, AnyPlatform: true}
+}
+
+// RoslynAnalyzerLabel 為 Unity 辨識 Roslyn analyzer 與 source generator 的資產標籤
+const RoslynAnalyzerLabel = "RoslynAnalyzer"
+
+// AnalyzerSettings 為 Roslyn analyzer 的設定：帶有 RoslynAnalyzer 標籤且所有平台（含 Editor）皆停用
+func AnalyzerSettings() PluginSettings {
+	return PluginSettings{Labels: []string{RoslynAnalyzerLabel}}
+}
+
+// NewPluginSettings 依平台名稱建立設定：include 為空時啟用於所有平台（exclude 中的除外），
+// 否則只啟用於 include 中的平台
+func NewPluginSettings(include, exclude []string) (PluginSettings, error) {
+	settings := DefaultPluginSettings()
+	for _, name := range include {
+		target, err := ParseBuildTarget(name)
+		if err != nil {
+			return settings, err
+		}
+		settings.Include = append(settings.Include, PlatformSetting{Target: target})
+	}
+	settings.AnyPlatform = len(settings.Include) == 0
+	for _, name := range exclude {
+		target, err := ParseBuildTarget(name)
+		if err != nil {
+			return settings, err
+		}
+		settings.Exclude = append(settings.Exclude, target)
+	}
+	return settings, nil
+}
+
+// Restrict 回傳只在 targets 中（且原本即啟用）的平台啟用的設定，其餘欄位沿用原設定
+func (s PluginSettings) Restrict(targets []PlatformSetting) PluginSettings {
+	restricted := s
+	restricted.AnyPlatform = false
+	restricted.Exclude = nil
+	restricted.Include = nil
+	for _, target := range targets {
+		if _, enabled := s.enabled(target.Target); enabled {
+			restricted.Include = append(restricted.Include, target)
+		}
+	}
+	return restricted
+}
+
+// Without 回傳停用 targets 中各平台的設定
+func (s PluginSettings) Without(targets ...BuildTarget) PluginSettings {
+	without := s
+	without.Exclude = append(append([]BuildTarget(nil), s.Exclude...), targets...)
+	without.Include = nil
+	for _, setting := range s.Include {
+		excluded := false
+		for _, target := range targets {
+			if setting.Target == target {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			without.Include = append(without.Include, setting)
+		}
+	}
+	return without
+}
+
+// WithCPU 回傳 target 只在 cpu 啟用的設定；target 原本未啟用時不變
+func (s PluginSettings) WithCPU(target BuildTarget, cpu string) PluginSettings {
+	setting, enabled := s.enabled(target)
+	if !enabled {
+		return s
+	}
+	setting.CPU = cpu
+	with := s
+	with.Include = []PlatformSetting{setting}
+	for _, included := range s.Include {
+		if included.Target != target {
+			with.Include = append(with.Include, included)
+		}
+	}
+	return with
+}
+
+func (s PluginSettings) isExcluded(target BuildTarget) bool {
+	for _, excluded := range s.Exclude {
+		if excluded == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (s PluginSettings) included(target BuildTarget) (PlatformSetting, bool) {
+	for _, setting := range s.Include {
+		if setting.Target == target {
+			return setting, true
+		}
+	}
+	return PlatformSetting{}, false
+}
+
+// enabled 回傳平台是否啟用以及該平台的 CPU / OS 設定
+func (s PluginSettings) enabled(target BuildTarget) (PlatformSetting, bool) {
+	if setting, ok := s.included(target); ok {
+		return setting, true
+	}
+	if s.AnyPlatform && !s.isExcluded(target) {
+		return PlatformSetting{Target: target}, true
+	}
+	return PlatformSetting{Target: target}, false
+}
+
+// platformKey 回傳 platformData 中 "first" 的內容
+func platformKey(target BuildTarget) string {
+	switch target {
+	case TargetEditor:
+		return "Editor: Editor"
+	case TargetWin, TargetWin64, TargetOSXUniversal, TargetLinux64:
+		return "Standalone: " + string(target)
+	case TargetAndroid:
+		return "Android: Android"
+	case TargetIOS:
+		return "iPhone: iOS"
+	case TargetWindowsStoreApps:
+		return "Windows Store Apps: WindowsStoreApps"
+	}
+	return string(target) + ": " + string(target)
+}
+
+// defaultCPU 為各平台在 meta 中的預設 CPU
+func defaultCPU(target BuildTarget) string {
+	switch target {
+	case TargetWin:
+		return "x86"
+	case TargetWin64:
+		return "x86_64"
+	case TargetAndroid:
+		return "ARMv7"
+	}
+	return "AnyCPU"
+}
+
+// PluginImporterMeta 產生 DLL 或 native plugin 的 PluginImporter meta
+func PluginImporterMeta(guid string, settings PluginSettings) []byte {
+	return pluginImporterMeta(guid, settings, false)
+}
+
+// pluginImporterMeta 產生 PluginImporter meta；folder 用於 .bundle、.framework 等以資料夾形式存在的 plugin
+func pluginImporterMeta(guid string, settings PluginSettings, folder bool) []byte {
+	var b strings.Builder
+	writeMetaHeader(&b, guid, settings.Labels)
+	if folder {
+		b.WriteString("folderAsset: yes\n")
+	}
+	b.WriteString("PluginImporter:\n")
+	b.WriteString("  externalObjects: {}\n")
+	b.WriteString("  serializedVersion: 2\n")
+	b.WriteString("  iconMap: {}\n")
+	b.WriteString("  executionOrder: {}\n")
+	if len(settings.DefineConstraints) == 0 {
+		b.WriteString("  defineConstraints: []\n")
+	} else {
+		b.WriteString("  defineConstraints:\n")
+		for _, define := range settings.DefineConstraints {
+			fmt.Fprintf(&b, "  - %s\n", yamlScalar(define))
+		}
+	}
+	fmt.Fprintf(&b, "  isPreloaded: %d\n", boolToInt(settings.IsPreloaded))
+	b.WriteString("  isOverridable: 0\n")
+	fmt.Fprintf(&b, "  isExplicitlyReferenced: %d\n", boolToInt(settings.IsExplicitlyReferenced))
+	fmt.Fprintf(&b, "  validateReferences: %d\n", boolToInt(settings.ValidateReferences))
+	b.WriteString("  platformData:\n")
+
+	// ": Any" 項目記錄 Any Platform 模式下各平台的排除狀態
+	b.WriteString("  - first:\n      : Any\n    second:\n      enabled: 0\n      settings:\n")
+	for _, target := range buildTargets {
+		_, enabled := settings.enabled(target)
+		fmt.Fprintf(&b, "        Exclude %s: %d\n", target, boolToInt(!enabled))
+	}
+	fmt.Fprintf(&b, "  - first:\n      Any: \n    second:\n      enabled: %d\n      settings: {}\n", boolToInt(settings.AnyPlatform))
+
+	for _, target := range buildTargets {
+		setting, enabled := settings.enabled(target)
+		fmt.Fprintf(&b, "  - first:\n      %s\n    second:\n      enabled: %d\n", platformKey(target), boolToInt(enabled))
+		writePlatformSettings(&b, setting)
+	}
+	writeMetaFooter(&b)
+	return []byte(b.String())
+}
+
+func writePlatformSettings(b *strings.Builder, setting PlatformSetting) {
+	cpu := setting.CPU
+	if cpu == "" {
+		cpu = defaultCPU(setting.Target)
+	}
+	switch setting.Target {
+	case TargetEditor:
+		os := setting.OS
+		if os == "" {
+			os = "AnyOS"
+		}
+		fmt.Fprintf(b, "      settings:\n        CPU: %s\n        DefaultValueInitialized: true\n        OS: %s\n", cpu, os)
+	case TargetIOS:
+		fmt.Fprintf(b, "      settings:\n        AddToEmbeddedBinaries: false\n        CPU: %s\n        CompileFlags: \n        FrameworkDependencies: \n", cpu)
+	case TargetWebGL:
+		b.WriteString("      settings: {}\n")
+	default:
+		fmt.Fprintf(b, "      settings:\n        CPU: %s\n", cpu)
+	}
+}
+
+// FolderMeta 產生資料夾的 meta
+func FolderMeta(guid string) []byte {
+	var b strings.Builder
+	writeMetaHeader(&b, guid, nil)
+	b.WriteString("folderAsset: yes\n")
+	b.WriteString("DefaultImporter:\n  externalObjects: {}\n")
+	writeMetaFooter(&b)
+	return []byte(b.String())
+}
+
+// importerMeta 產生只有 externalObjects 的 importer meta（DefaultImporter、TextScriptImporter 等）
+func importerMeta(guid, importer string) []byte {
+	var b strings.Builder
+	writeMetaHeader(&b, guid, nil)
+	fmt.Fprintf(&b, "%s:\n  externalObjects: {}\n", importer)
+	writeMetaFooter(&b)
+	return []byte(b.String())
+}
+
+// monoImporterMeta 產生 C# 腳本的 meta
+func monoImporterMeta(guid string) []byte {
+	var b strings.Builder
+	writeMetaHeader(&b, guid, nil)
+	b.WriteString("MonoImporter:\n  externalObjects: {}\n  serializedVersion: 2\n  defaultReferences: []\n  executionOrder: 0\n  icon: {instanceID: 0}\n")
+	writeMetaFooter(&b)
+	return []byte(b.String())
+}
+
+// GenerateMeta 依資產類型產生對應 importer 的 meta。
+// settings 僅用於 plugin（.dll 與 native library），為 nil 時使用 DefaultPluginSettings。
+func GenerateMeta(guid, assetPath string, isDir bool, settings *PluginSettings) []byte {
+	name := strings.ToLower(path.Base(assetPath))
+	if isDir {
+		if isNativePlugin(name) {
+			return pluginImporterMeta(guid, pluginSettingsOrDefault(settings), true)
+		}
+		return FolderMeta(guid)
+	}
+
+	switch ext := path.Ext(name); {
+	case name == "package.json":
+		return importerMeta(guid, "PackageManifestImporter")
+	case ext == ".asmdef":
+		return importerMeta(guid, "AssemblyDefinitionImporter")
+	case ext == ".cs":
+		return monoImporterMeta(guid)
+	case ext == ".asmref":
+		return importerMeta(guid, "AssemblyDefinitionReferenceImporter")
+	case ext == ".json", ext == ".xml", ext == ".txt", ext == ".md", ext == ".bytes":
+		return importerMeta(guid, "TextScriptImporter")
+	case ext == ".dll", isNativePlugin(name):
+		return PluginImporterMeta(guid, pluginSettingsOrDefault(settings))
+	}
+	return importerMeta(guid, "DefaultImporter")
+}
+
+func pluginSettingsOrDefault(settings *PluginSettings) PluginSettings {
+	if settings == nil {
+		return DefaultPluginSettings()
+	}
+	return *settings
+}
+
+// isNativePlugin 判斷檔名（或 .bundle、.framework 等資料夾名稱）是否為 Unity 以 PluginImporter 匯入的 native library
+func isNativePlugin(name string) bool {
+	switch path.Ext(name) {
+	case ".so", ".dylib", ".a", ".bundle", ".jnilib", ".aar", ".jar", ".framework", ".xcframework", ".plugin":
+		return true
+	}
+	return false
+}
+
+func writeMetaHeader(b *strings.Builder, guid string, labels []string) {
+	fmt.Fprintf(b, "fileFormatVersion: 2\nguid: %s\n", guid)
+	if len(labels) > 0 {
+		b.WriteString("labels:\n")
+		for _, label := range labels {
+			fmt.Fprintf(b, "- %s\n", yamlScalar(label))
+		}
+	}
+}
+
+func writeMetaFooter(b *strings.Builder) {
+	b.WriteString("  userData: \n  assetBundleName: \n  assetBundleVariant: \n")
+}
+
+// yamlScalar 在值以 YAML 保留字元開頭時加上單引號（例如 define constraint "!DEBUG"）
+func yamlScalar(s string) string {
+	if s == "" || strings.ContainsAny(s[:1], "!&*'\"%@`|>{}[],#?:-") || strings.Contains(s, ": ") {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return s
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package unitypackage
+
+import (
+	"strings"
+)
+
+// RuntimePlatform 為 NuGet runtime identifier (RID) 對應的 Unity 平台
+type RuntimePlatform struct {
+	Folder  string            // Plugins/ 下存放該 RID 檔案的資料夾，例如 "Windows/x86_64"
+	Targets []PlatformSetting // 啟用的 build target 與 CPU
+}
+
+// ridArchitectures 為 RID 的架構後綴與 Unity 各平台使用的 CPU 名稱
+var ridArchitectures = map[string]struct {
+	folder     string // Plugins/<platform>/ 下的資料夾
+	standalone string // Standalone 與 Editor 的 CPU
+	android    string // Android 的 CPU 與 ABI 資料夾
+	abi        string
+}{
+	"x64":   {"x86_64", "x86_64", "X86_64", "x86_64"},
+	"x86":   {"x86", "x86", "X86", "x86"},
+	"arm64": {"arm64", "ARM64", "ARM64", "arm64-v8a"},
+	"arm":   {"arm", "", "ARMv7", "armeabi-v7a"},
+}
+
+// targetCPUs 為可依 CPU 分別啟用的平台及其 CPU 名稱（不含 AnyCPU）
+var targetCPUs = map[BuildTarget][]string{
+	TargetWin64:        {"x86_64", "ARM64"},
+	TargetOSXUniversal: {"x86_64", "ARM64"},
+	TargetAndroid:      {"ARMv7", "ARM64", "X86", "X86_64"},
+}
+
+// TargetCPUs 回傳 target 可分別啟用的 CPU；只有一種 CPU 的平台（如 Win、Linux64）回傳 nil
+func TargetCPUs(target BuildTarget) []string {
+	return targetCPUs[target]
+}
+
+// PlatformForRID 回傳 RID（例如 win-x64、osx-arm64、android-arm64、ios、unix）對應的 Unity 平台。
+// Editor 只在 64 位元的桌面平台啟用；Unity 不支援的 RID（如 linux-musl-x64、tvos）回傳 false。
+func PlatformForRID(rid string) (RuntimePlatform, bool) {
+	system, arch := splitRID(strings.ToLower(rid))
+	archInfo, hasArch := ridArchitectures[arch]
+
+	switch system {
+	case "win":
+		switch arch {
+		case "":
+			return RuntimePlatform{Folder: "Windows", Targets: []PlatformSetting{
+				{Target: TargetWin, CPU: "x86"},
+				{Target: TargetWin64, CPU: "x86_64"},
+				{Target: TargetEditor, CPU: "AnyCPU", OS: "Windows"},
+			}}, true
+		case "x64":
+			return RuntimePlatform{Folder: "Windows/x86_64", Targets: []PlatformSetting{
+				{Target: TargetWin64, CPU: "x86_64"},
+				{Target: TargetEditor, CPU: "x86_64", OS: "Windows"},
+			}}, true
+		case "x86":
+			return RuntimePlatform{Folder: "Windows/x86", Targets: []PlatformSetting{
+				{Target: TargetWin, CPU: "x86"},
+			}}, true
+		case "arm64":
+			return RuntimePlatform{Folder: "Windows/arm64", Targets: []PlatformSetting{
+				{Target: TargetWin64, CPU: "ARM64"},
+			}}, true
+		}
+	case "osx":
+		switch arch {
+		case "":
+			return RuntimePlatform{Folder: "macOS", Targets: []PlatformSetting{
+				{Target: TargetOSXUniversal, CPU: "AnyCPU"},
+				{Target: TargetEditor, CPU: "AnyCPU", OS: "OSX"},
+			}}, true
+		case "x64", "arm64":
+			return RuntimePlatform{Folder: "macOS/" + archInfo.folder, Targets: []PlatformSetting{
+				{Target: TargetOSXUniversal, CPU: archInfo.standalone},
+				{Target: TargetEditor, CPU: archInfo.standalone, OS: "OSX"},
+			}}, true
+		}
+	case "unix":
+		if arch == "" {
+			return RuntimePlatform{Folder: "Unix", Targets: []PlatformSetting{
+				{Target: TargetLinux64, CPU: "x86_64"},
+				{Target: TargetOSXUniversal, CPU: "AnyCPU"},
+			}}, true
+		}
+	case "linux":
+		if arch == "" {
+			return RuntimePlatform{Folder: "Linux", Targets: []PlatformSetting{
+				{Target: TargetLinux64, CPU: "x86_64"},
+				{Target: TargetEditor, CPU: "x86_64", OS: "Linux"},
+			}}, true
+		}
+		if arch == "x64" {
+			return RuntimePlatform{Folder: "Linux/x86_64", Targets: []PlatformSetting{
+				{Target: TargetLinux64, CPU: "x86_64"},
+				{Target: TargetEditor, CPU: "x86_64", OS: "Linux"},
+			}}, true
+		}
+	case "android":
+		if arch == "" {
+			return RuntimePlatform{Folder: "Android", Targets: []PlatformSetting{
+				{Target: TargetAndroid},
+			}}, true
+		}
+		if hasArch {
+			return RuntimePlatform{Folder: "Android/" + archInfo.abi, Targets: []PlatformSetting{
+				{Target: TargetAndroid, CPU: archInfo.android},
+			}}, true
+		}
+	case "ios":
+		if arch == "" || arch == "arm64" {
+			return RuntimePlatform{Folder: "iOS", Targets: []PlatformSetting{
+				{Target: TargetIOS, CPU: "AnyCPU"},
+			}}, true
+		}
+	case "browser":
+		if arch == "wasm" {
+			return RuntimePlatform{Folder: "WebGL", Targets: []PlatformSetting{
+				{Target: TargetWebGL},
+			}}, true
+		}
+	}
+	return RuntimePlatform{}, false
+}
+
+// splitRID 將 RID 拆成作業系統與架構，並去掉版本號（win7-x64 → win、x64；osx.10.12-x64 → osx、x64）
+func splitRID(rid string) (system, arch string) {
+	system = rid
+	if i := strings.LastIndex(rid, "-"); i >= 0 {
+		system, arch = rid[:i], rid[i+1:]
+		if _, ok := ridArchitectures[arch]; !ok && arch != "wasm" {
+			// 沒有架構後綴的 RID（例如 linux-musl）保留原樣，交由呼叫端判斷為不支援
+			system, arch = rid, ""
+		}
+	}
+	if i := strings.Index(system, "."); i >= 0 {
+		system = system[:i]
+	}
+	system = strings.TrimRight(system, "0123456789")
+	return system, arch
+}
@@ -0,0 +1,40 @@
+package unitypackage
+
+import (
+	"archive/tar"
+	"os"
+)
+
+// CreateUPMTarball 將 exportDir（根目錄需有 package.json）打包成 npm 格式的 UPM 套件 .tgz：
+// 所有檔案位於 package/ 之下，除根目錄外的每個檔案與資料夾都附上 .meta。
+// upmName 為 package.json 中的套件名稱，既有 GUID 先以 upmName 查找，再以 packageName 查找，
+// 因此從 .unitypackage 改用 UPM 套件時仍沿用原本的 GUID。
+func CreateUPMTarball(exportDir, packageName, upmName, outPath string, opts PackOptions) error {
+	assets, err := collectAssets(exportDir, packageName, []string{upmName, packageName}, opts)
+	if err != nil {
+		return err
+	}
+
+	return createTarGz(outPath, func(tarWriter *tar.Writer) error {
+		for _, a := range assets {
+			// UPM 套件的根目錄本身沒有 meta
+			if a.rel == "" {
+				continue
+			}
+			name := "package/" + a.rel
+			if !a.isDir {
+				content, err := os.ReadFile(a.path)
+				if err != nil {
+					return err
+				}
+				if err := writeTarFile(tarWriter, name, content); err != nil {
+					return err
+				}
+			}
+			if err := writeTarFile(tarWriter, name+".meta", a.meta); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+package unitypackage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateUPMTarball(t *testing.T) {
+	exportDir := t.TempDir()
+	files := map[string]string{
+		"package.json":     `{"name": "com.nuget.foo"}`,
+		"Runtime/Foo.dll":  "dll",
+		"Runtime/link.xml": "<linker/>",
+	}
+	for name, content := range files {
+		path := filepath.Join(exportDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outPath := filepath.Join(t.TempDir(), "com.nuget.foo-1.0.0.tgz")
+	if err := CreateUPMTarball(exportDir, "Foo", "com.nuget.foo", outPath, PackOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 讀到 gzip 結尾沒有錯誤，代表 tar 與 gzip 都已完整關閉
+	f, err := os.Open(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	entries := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[header.Name] = string(data)
+	}
+	for name, content := range files {
+		if entries["package/"+name] != content {
+			t.Errorf("package/%s = %q, want %q", name, entries["package/"+name], content)
+		}
+		if name != "package.json" {
+			if _, ok := entries["package/"+name+".meta"]; !ok {
+				t.Errorf("package/%s has no .meta", name)
+			}
+		}
+	}
+	if _, ok := entries["package/Runtime.meta"]; !ok {
+		t.Error("the Runtime folder has no .meta")
+	}
+}
+
+func TestCreateUPMTarballError(t *testing.T) {
+	exportDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(exportDir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(t.TempDir(), "missing", "out.tgz")
+	if err := CreateUPMTarball(exportDir, "Foo", "com.nuget.foo", outPath, PackOptions{}); err == nil {
+		t.Error("CreateUPMTarball succeeded writing into a missing directory")
+	}
+}
@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/nuget"
+	"github.com/Harrison-Dev/nuget-2-dll-go/internal/packagemanifest"
+)
+
+// UPMDependencies 回傳以 UPM 套件表示 spec 的依賴時 package.json 的 dependencies：
+// 適用於 targets 的直接依賴中未被 resolver.Filter 排除者（Unity 內建或使用者拒絕），
+// 對應為 com.nuget.* 與依 NuGet 規則從 feed 選出的版本
+func UPMDependencies(resolver *nuget.Resolver, spec *nuget.Nuspec, targets []nuget.Framework) (map[string]string, error) {
+	framework := spec.DependencyFramework(targets)
+	versions, err := resolver.SelectVersions(spec.Metadata.ID+" "+spec.Metadata.Version, spec.DependenciesFor(framework.ShortFolderName()))
+	if err != nil {
+		return nil, err
+	}
+	dependencies := map[string]string{}
+	for id, version := range versions {
+		upmVersion, err := packagemanifest.UPMVersion(version.String())
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", id, err)
+		}
+		dependencies[packagemanifest.PackageName(id)] = upmVersion
+	}
+	return dependencies, nil
+}
@@ -1,35 +1,26 @@
 package utils
 
 import (
-	"fmt"
-	"math/rand"
-	"time"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
+// guidNamespace 為產生 name-based UUID 時使用的固定 namespace
+var guidNamespace = []byte{0x6b, 0x5e, 0x2a, 0x0c, 0x8d, 0x31, 0x4f, 0x7e, 0x9a, 0x13, 0x52, 0xd4, 0xe0, 0x6f, 0x1b, 0x87}
 
-// GenGUID 產生簡單的假GUID (32 hex chars)
-func GenGUID() string {
-	const hexChars = "0123456789abcdef"
-	b := make([]byte, 32)
-	for i := 0; i < 32; i++ {
-		b[i] = hexChars[rand.Intn(len(hexChars))]
-	}
-	return string(b)
+// StableGUID 依名稱產生固定的 GUID (UUID v5，32 hex chars)，相同名稱永遠得到相同結果
+func StableGUID(name string) string {
+	h := sha1.New()
+	h.Write(guidNamespace)
+	h.Write([]byte(name))
+	sum := h.Sum(nil)[:16]
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return hex.EncodeToString(sum)
 }
 
-// GenerateMeta 為一個資產產生簡單的meta檔案內容
-func GenerateMeta(guid string) []byte {
-	return []byte(fmt.Sprintf(`fileFormatVersion: 2
-guid: %s
-timeCreated: %d
-licenseType: Free
-DefaultImporter:
-  externalObjects: {}
-  userData: 
-  assetBundleName: 
-  assetBundleVariant: 
-`, guid, time.Now().Unix()))
+// AssetGUID 以套件 id 與資產在套件內的相對路徑產生 GUID，重新匯出同一套件時保持不變
+func AssetGUID(packageID, assetPath string) string {
+	return StableGUID(strings.ToLower(packageID) + "/" + strings.ReplaceAll(assetPath, "\\", "/"))
 }
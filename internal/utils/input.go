@@ -7,8 +7,11 @@ import (
 	"strings"
 )
 
+// stdinReader 於多次提示間共用，避免以管線輸入時第一個 reader 吃掉後續行
+var stdinReader = bufio.NewReader(os.Stdin)
+
 func GetUserInput(prompt string, defaultValue string) string {
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdinReader
 	if defaultValue != "" {
 		fmt.Printf("%s [%s]: ", prompt, defaultValue)
 	} else {
@@ -21,3 +24,14 @@ func GetUserInput(prompt string, defaultValue string) string {
 	}
 	return input
 }
+
+// SplitList 將以逗號分隔的字串拆成清單，忽略空白項目
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}